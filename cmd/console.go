@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/cli"
+	"github.com/phogolabs/prana/sqlexec"
+	"github.com/phogolabs/prana/storage"
+)
+
+// SQLConsole provides an interactive shell for the configured database.
+type SQLConsole struct {
+	db      *sqlx.DB
+	console *sqlexec.Console
+}
+
+// CreateCommand creates a cli.Command that can be used by cli.App.
+func (m *SQLConsole) CreateCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "console",
+		Usage:       "Run an interactive shell for SQL statements and routines",
+		Description: "Run an interactive shell for SQL statements and routines. Type \\? for help",
+		Action:      m.run,
+		Before:      m.before,
+		After:       m.after,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:   "routine-dir, d",
+				Usage:  "path to the directory that contain the SQL routines",
+				EnvVar: "PRANA_ROUTINE_DIR",
+				Value:  "./database/routine",
+			},
+			&cli.StringFlag{
+				Name:  "format, f",
+				Usage: "format of the output (table, json, ndjson, csv, tsv, yaml, markdown)",
+				Value: sqlexec.FormatTable,
+			},
+			&cli.StringFlag{
+				Name:  "binary",
+				Usage: "encoding of the binary values (text, hex, base64)",
+				Value: sqlexec.BinaryText,
+			},
+			&cli.StringFlag{
+				Name:   "history-file",
+				Usage:  "path to the file that contains the history",
+				EnvVar: "PRANA_HISTORY_FILE",
+				Value:  history(),
+			},
+		},
+	}
+}
+
+func (m *SQLConsole) before(ctx *cli.Context) error {
+	dir, err := filepath.Abs(ctx.String("routine-dir"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeArg)
+	}
+
+	db, err := open(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.db = db
+
+	m.console = &sqlexec.Console{
+		Runner: &sqlexec.Runner{
+			FileSystem: storage.New(dir),
+			DB:         db,
+		},
+		Printer: &sqlexec.Printer{
+			Format: ctx.String("format"),
+			Binary: ctx.String("binary"),
+		},
+		Writer:      os.Stdout,
+		HistoryFile: ctx.String("history-file"),
+	}
+
+	return nil
+}
+
+func (m *SQLConsole) run(ctx *cli.Context) error {
+	if err := m.console.Run(); err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeCommand)
+	}
+
+	return nil
+}
+
+func (m *SQLConsole) after(ctx *cli.Context) error {
+	if m.db != nil {
+		if err := m.db.Close(); err != nil {
+			return cli.NewExitError(err.Error(), ErrCodeCommand)
+		}
+	}
+
+	return nil
+}
+
+// history returns the default path to the history file
+func history() string {
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, ".prana_history")
+}
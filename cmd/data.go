@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/cli"
+	"github.com/phogolabs/log"
+	"github.com/phogolabs/prana/sqldata"
+	"github.com/phogolabs/prana/sqlmodel"
+)
+
+// SQLData provides a subcommands to export, import and copy the data of tables
+type SQLData struct {
+	db       *sqlx.DB
+	target   *sqlx.DB
+	provider sqlmodel.SchemaProvider
+}
+
+// CreateCommand creates a cli.Command that can be used by cli.App.
+func (m *SQLData) CreateCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "data",
+		Usage:       "A group of commands for exporting, importing and copying the data of database tables",
+		Description: "A group of commands for exporting, importing and copying the data of database tables",
+		Commands: []*cli.Command{
+			&cli.Command{
+				Name:        "export",
+				Usage:       "Export the rows of a table",
+				Description: "Export the rows of a table",
+				Action:      m.export,
+				Before:      m.before,
+				After:       m.after,
+				Flags: append(m.flags(), &cli.StringFlag{
+					Name:  "output, o",
+					Usage: "path to the file, where the rows will be written. Default to stdout",
+				}),
+			},
+			&cli.Command{
+				Name:        "import",
+				Usage:       "Import the rows of a table in a single transaction",
+				Description: "Import the rows of a table in a single transaction",
+				Action:      m.load,
+				Before:      m.before,
+				After:       m.after,
+				Flags: append(m.flags(), &cli.StringFlag{
+					Name:  "input, i",
+					Usage: "path to the file, where the rows will be read from. Default to stdin",
+				}),
+			},
+			&cli.Command{
+				Name:        "copy",
+				Usage:       "Copy the tables to another database",
+				Description: "Copy the tables to another database. The tables are created in the target database and the row counts are verified before the commit",
+				Action:      m.copy,
+				Before:      m.beforeCopy,
+				After:       m.after,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "URL of the source database. Default to the database-url",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "URL of the target database",
+					},
+					&cli.StringFlag{
+						Name:  "schema-name, s",
+						Usage: "name of the source database schema",
+						Value: "",
+					},
+					&cli.StringSliceFlag{
+						Name:  "table, t",
+						Usage: "name of the table in the source database. Default to all tables",
+					},
+					&cli.BoolFlag{
+						Name:  "truncate",
+						Usage: "delete the rows of the existing target tables before the copy",
+					},
+					&cli.IntFlag{
+						Name:  "batch-size, b",
+						Usage: "number of the rows processed at once",
+						Value: sqldata.DefaultBatchSize,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (m *SQLData) flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "schema-name, s",
+			Usage: "name of the database schema",
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name:  "table, t",
+			Usage: "name of the table in the database",
+		},
+		&cli.StringFlag{
+			Name:  "format, f",
+			Usage: "format of the data (csv, json, sql)",
+			Value: sqldata.FormatCSV,
+		},
+		&cli.IntFlag{
+			Name:  "batch-size, b",
+			Usage: "number of the rows processed at once",
+			Value: sqldata.DefaultBatchSize,
+		},
+	}
+}
+
+func (m *SQLData) before(ctx *cli.Context) error {
+	if ctx.String("table") == "" {
+		return cli.NewExitError("The table name is not provided", ErrCodeArg)
+	}
+
+	db, err := open(ctx)
+	if err != nil {
+		return err
+	}
+
+	m.db = db
+
+	if m.provider, err = provider(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *SQLData) beforeCopy(ctx *cli.Context) error {
+	if ctx.String("to") == "" {
+		return cli.NewExitError("The target database URL is not provided", ErrCodeArg)
+	}
+
+	from := ctx.String("from")
+
+	if from == "" {
+		from = ctx.GlobalString("database-url")
+	}
+
+	db, err := openURL(from)
+	if err != nil {
+		return err
+	}
+
+	m.db = db
+
+	if m.provider, err = provider(db); err != nil {
+		return err
+	}
+
+	if m.target, err = openURL(ctx.String("to")); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *SQLData) after(ctx *cli.Context) error {
+	if m.target != nil {
+		if err := m.target.Close(); err != nil {
+			return cli.NewExitError(err.Error(), ErrCodeData)
+		}
+	}
+
+	if m.provider != nil {
+		if err := m.provider.Close(); err != nil {
+			return cli.NewExitError(err.Error(), ErrCodeData)
+		}
+	}
+
+	return nil
+}
+
+func (m *SQLData) export(ctx *cli.Context) error {
+	var (
+		table  = ctx.String("table")
+		writer = io.Writer(os.Stdout)
+	)
+
+	if path := ctx.String("output"); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return cli.NewExitError(err.Error(), ErrCodeArg)
+		}
+
+		defer file.Close()
+		writer = file
+	}
+
+	exporter := &sqldata.Exporter{
+		DB:        m.db,
+		Provider:  m.provider,
+		Schema:    ctx.String("schema-name"),
+		Format:    ctx.String("format"),
+		BatchSize: ctx.Int("batch-size"),
+		Progress: func(count int64) {
+			log.Infof("Exported %d rows from table '%s'", count, table)
+		},
+	}
+
+	count, err := exporter.Export(writer, table)
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeData)
+	}
+
+	log.Infof("Exported %d rows from table '%s' successfully", count, table)
+	return nil
+}
+
+func (m *SQLData) load(ctx *cli.Context) error {
+	var (
+		table  = ctx.String("table")
+		reader = io.Reader(os.Stdin)
+	)
+
+	if path := ctx.String("input"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return cli.NewExitError(err.Error(), ErrCodeArg)
+		}
+
+		defer file.Close()
+		reader = file
+	}
+
+	importer := &sqldata.Importer{
+		DB:        m.db,
+		Provider:  m.provider,
+		Schema:    ctx.String("schema-name"),
+		Format:    ctx.String("format"),
+		BatchSize: ctx.Int("batch-size"),
+		Progress: func(count int64) {
+			log.Infof("Imported %d rows into table '%s'", count, table)
+		},
+	}
+
+	count, err := importer.Import(reader, table)
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeData)
+	}
+
+	log.Infof("Imported %d rows into table '%s' successfully", count, table)
+	return nil
+}
+
+func (m *SQLData) copy(ctx *cli.Context) error {
+	copier := &sqldata.Copier{
+		Source:    m.db,
+		Target:    m.target,
+		Provider:  m.provider,
+		Schema:    ctx.String("schema-name"),
+		Tables:    ctx.StringSlice("table"),
+		Truncate:  ctx.Bool("truncate"),
+		BatchSize: ctx.Int("batch-size"),
+		Progress: func(table string, count int64) {
+			log.Infof("Copied %d rows of table '%s'", count, table)
+		},
+	}
+
+	results, err := copier.Copy()
+
+	for _, result := range results {
+		log.Infof("Verified %d rows of table '%s'", result.TargetCount, result.Table)
+	}
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeData)
+	}
+
+	log.Infof("Copied %d tables successfully", len(results))
+	return nil
+}
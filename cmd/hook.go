@@ -24,6 +24,8 @@ const (
 	ErrCodeCommand = 104
 	// ErrCodeSchema when the SQL schema operation fails.
 	ErrCodeSchema = 105
+	// ErrCodeData when the data operation fails.
+	ErrCodeData = 106
 )
 
 type logHandler struct {
@@ -58,7 +60,11 @@ func BeforeEach(ctx *cli.Context) error {
 }
 
 func open(ctx *cli.Context) (*sqlx.DB, error) {
-	driver, conn, err := prana.ParseURL(ctx.GlobalString("database-url"))
+	return openURL(ctx.GlobalString("database-url"))
+}
+
+func openURL(url string) (*sqlx.DB, error) {
+	driver, conn, err := prana.ParseURL(url)
 	if err != nil {
 		return nil, cli.NewExitError(err.Error(), ErrCodeArg)
 	}
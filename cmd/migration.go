@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
@@ -15,11 +17,18 @@ import (
 
 // SQLMigration provides a subcommands to work with SQL migrations.
 type SQLMigration struct {
+	// Version is the prana version recorded in the migrations history.
+	Version string
+
 	executor *sqlmigr.Executor
 	db       *sqlx.DB
+	dbs      []*sqlx.DB
 	dir      string
+	actor    string
 }
 
+var passwordRgxp = regexp.MustCompile(`(//[^:/@]+):[^@/]*@`)
+
 // CreateCommand creates a cli.Command that can be used by cli.App.
 func (m *SQLMigration) CreateCommand() *cli.Command {
 	return &cli.Command{
@@ -36,6 +45,11 @@ func (m *SQLMigration) CreateCommand() *cli.Command {
 				Value:    "./database/migration",
 				Required: true,
 			},
+			&cli.StringFlag{
+				Name:   "actor",
+				Usage:  "name of the user recorded in the migrations history. Default to the current OS user",
+				EnvVar: "PRANA_MIGRATION_ACTOR",
+			},
 		},
 		Commands: []*cli.Command{
 			{
@@ -43,6 +57,7 @@ func (m *SQLMigration) CreateCommand() *cli.Command {
 				Usage:       "Setup the migration for the current project",
 				Description: "Configure the current project by creating database directory hierarchy and initial migration",
 				Action:      m.setup,
+				Before:      m.open,
 			},
 			{
 				Name:        "create",
@@ -50,74 +65,127 @@ func (m *SQLMigration) CreateCommand() *cli.Command {
 				Description: "Create a new migration file for the given name, and the current timestamp as the version in database/migration directory",
 				ArgsUsage:   "[name]",
 				Action:      m.create,
+				Before:      m.open,
 			},
 			{
 				Name:   "run",
 				Usage:  "Run the pending migrations",
 				Action: m.run,
-				Flags: []cli.Flag{
+				Before: m.open,
+				Flags: append([]cli.Flag{
 					&cli.IntFlag{
 						Name:  "count, c",
 						Usage: "Number of migrations to be executed. Negative number will run all",
 						Value: -1,
 					},
-				},
+					&cli.StringFlag{
+						Name:  "backup-dir",
+						Usage: "path to the directory where a database snapshot is taken before running the migrations",
+					},
+				}, m.groupFlags()...),
 			},
 			{
 				Name:   "revert",
 				Usage:  "Revert the latest applied migrations",
 				Action: m.revert,
-				Flags: []cli.Flag{
+				Before: m.open,
+				Flags: append([]cli.Flag{
 					&cli.IntFlag{
 						Name:  "count, c",
 						Usage: "Number of migrations to be reverted. Negative number will revert all",
 						Value: -1,
 					},
-				},
+				}, m.groupFlags()...),
 			},
 			{
 				Name:   "reset",
 				Usage:  "Revert and re-run all migrations",
 				Action: m.reset,
+				Before: m.open,
 			},
 			{
 				Name:   "status",
 				Usage:  "Show all migrations, marking those that have been applied",
 				Action: m.status,
+				Before: m.open,
+				Flags:  m.groupFlags(),
+			},
+			{
+				Name:   "plan",
+				Usage:  "Show the execution plan of the pending migrations",
+				Action: m.plan,
+				Before: m.open,
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "count, c",
+						Usage: "Number of migrations to be planned. Negative number will plan all",
+						Value: -1,
+					},
+					&cli.BoolFlag{
+						Name:  "include-sql",
+						Usage: "include the statements of each migration",
+					},
+				},
+			},
+			{
+				Name:   "history",
+				Usage:  "Show the history of all run and reverted migrations",
+				Action: m.history,
+				Before: m.open,
 			},
 		},
 	}
 }
 
 func (m *SQLMigration) before(ctx *cli.Context) (err error) {
-	m.db, err = open(ctx)
+	m.dir, err = filepath.Abs(ctx.String("migration-dir"))
 	if err != nil {
-		return err
+		return cli.WrapError(err).WithCode(ErrCodeArg)
 	}
 
-	m.dir, err = filepath.Abs(ctx.String("migration-dir"))
+	// the flags of the parent command are not visible to the subcommands
+	m.actor = ctx.String("actor")
+	return nil
+}
+
+// open opens the default database unless the subcommand is executed against
+// a group of databases or schemas.
+func (m *SQLMigration) open(ctx *cli.Context) (err error) {
+	if m.grouped(ctx) {
+		return nil
+	}
+
+	m.db, err = open(ctx)
 	if err != nil {
-		return cli.WrapError(err).WithCode(ErrCodeArg)
+		return err
 	}
 
-	storage := storage.New(m.dir)
 	// executer setup
-	m.executor = &sqlmigr.Executor{
-		Logger: log.WithField("command", ctx.Command.Name),
+	m.executor = m.newExecutor(m.db, "", log.WithField("command", ctx.Command.Name))
+	return nil
+}
+
+func (m *SQLMigration) newExecutor(db *sqlx.DB, schema string, logger log.Logger) *sqlmigr.Executor {
+	storage := storage.New(m.dir)
+
+	return &sqlmigr.Executor{
+		Logger: logger,
 		Provider: &sqlmigr.Provider{
 			FileSystem: storage,
-			DB:         m.db,
+			DB:         db,
+			Actor:      m.actor,
+			Version:    m.Version,
+			Schema:     schema,
 		},
 		Runner: &sqlmigr.Runner{
 			FileSystem: storage,
-			DB:         m.db,
+			DB:         db,
+			Schema:     schema,
 		},
 		Generator: &sqlmigr.Generator{
 			FileSystem: storage,
 		},
 	}
-
-	return nil
 }
 
 func (m *SQLMigration) after(ctx *cli.Context) error {
@@ -127,6 +195,12 @@ func (m *SQLMigration) after(ctx *cli.Context) error {
 		}
 	}
 
+	for _, db := range m.dbs {
+		if err := db.Close(); err != nil {
+			return cli.NewExitError(err.Error(), ErrCodeMigration)
+		}
+	}
+
 	return nil
 }
 
@@ -162,7 +236,25 @@ func (m *SQLMigration) create(ctx *cli.Context) error {
 func (m *SQLMigration) run(ctx *cli.Context) error {
 	count := ctx.Int("count")
 
-	_, err := m.executor.Run(count)
+	group, err := m.group(ctx)
+	if err != nil {
+		return err
+	}
+
+	if group != nil {
+		return m.results(ctx, group.Run(count))
+	}
+
+	if dir := ctx.String("backup-dir"); dir != "" {
+		backup, err := m.backup(m.db, dir)
+		if err != nil {
+			return err
+		}
+
+		m.executor.Backup = backup
+	}
+
+	_, err = m.executor.Run(count)
 	if err != nil {
 		err = m.errf(err)
 		return cli.NewExitError(err.Error(), ErrCodeMigration)
@@ -174,7 +266,16 @@ func (m *SQLMigration) run(ctx *cli.Context) error {
 func (m *SQLMigration) revert(ctx *cli.Context) error {
 	count := ctx.Int("count")
 
-	_, err := m.executor.Revert(count)
+	group, err := m.group(ctx)
+	if err != nil {
+		return err
+	}
+
+	if group != nil {
+		return m.results(ctx, group.Revert(count))
+	}
+
+	_, err = m.executor.Revert(count)
 	if err != nil {
 		err = m.errf(err)
 		return cli.NewExitError(err.Error(), ErrCodeMigration)
@@ -200,6 +301,15 @@ func (m *SQLMigration) reset(ctx *cli.Context) error {
 }
 
 func (m *SQLMigration) status(ctx *cli.Context) error {
+	group, err := m.group(ctx)
+	if err != nil {
+		return err
+	}
+
+	if group != nil {
+		return m.results(ctx, group.Migrations())
+	}
+
 	migrations, err := m.executor.Migrations()
 	if err != nil {
 		return err
@@ -215,9 +325,265 @@ func (m *SQLMigration) status(ctx *cli.Context) error {
 	return nil
 }
 
+func (m *SQLMigration) plan(ctx *cli.Context) error {
+	plans, err := m.executor.Plan(ctx.Int("count"))
+	if err != nil {
+		err = m.errf(err)
+		return cli.NewExitError(err.Error(), ErrCodeMigration)
+	}
+
+	if strings.EqualFold("json", ctx.GlobalString("log-format")) {
+		logger := log.WithField("command", ctx.Command.Name)
+		sqlmigr.FlogPlans(logger, plans)
+		return nil
+	}
+
+	sqlmigr.FtablePlans(os.Stdout, plans)
+
+	if ctx.Bool("include-sql") {
+		sqlmigr.Fscript(os.Stdout, plans)
+	}
+
+	return nil
+}
+
+func (m *SQLMigration) history(ctx *cli.Context) error {
+	events, err := m.executor.History()
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeMigration)
+	}
+
+	if strings.EqualFold("json", ctx.GlobalString("log-format")) {
+		logger := log.WithField("command", ctx.Command.Name)
+		sqlmigr.FlogEvents(logger, events)
+		return nil
+	}
+
+	sqlmigr.FtableEvents(os.Stdout, events)
+	return nil
+}
+
+func (m *SQLMigration) backup(db *sqlx.DB, dir string) (sqlmigr.MigrationBackup, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, cli.NewExitError(err.Error(), ErrCodeArg)
+	}
+
+	switch db.DriverName() {
+	case "sqlite3":
+		return &sqlmigr.SQLiteBackup{Dir: dir, DB: db}, nil
+	default:
+		err := fmt.Errorf("Cannot find backup for database driver '%s'", db.DriverName())
+		return nil, cli.NewExitError(err.Error(), ErrCodeArg)
+	}
+}
+
 func (m *SQLMigration) errf(err error) error {
 	if os.IsNotExist(err) {
 		err = fmt.Errorf("Directory '%s' does not exist", m.dir)
 	}
 	return err
 }
+
+func (m *SQLMigration) groupFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "database-urls",
+			Usage: "URL of a database the command is executed against instead of the database-url. It can be repeated",
+		},
+		&cli.StringFlag{
+			Name:  "database-url-file",
+			Usage: "path to a file that contains a database URL per line",
+		},
+		&cli.IntFlag{
+			Name:  "concurrency",
+			Usage: "Number of databases processed at the same time",
+			Value: 1,
+		},
+		&cli.BoolFlag{
+			Name:  "continue-on-error",
+			Usage: "process the rest of the databases when the command fails for some of them",
+		},
+		&cli.StringSliceFlag{
+			Name:  "schema",
+			Usage: "PostgreSQL schema the command is executed against. It can be repeated",
+		},
+		&cli.StringFlag{
+			Name:  "all-schemas",
+			Usage: "LIKE pattern of the PostgreSQL schemas the command is executed against",
+		},
+	}
+}
+
+func (m *SQLMigration) group(ctx *cli.Context) (*sqlmigr.Group, error) {
+	urls, err := m.urls(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(urls) == 0 {
+		if !m.multischema(ctx) {
+			return nil, nil
+		}
+
+		urls = append(urls, ctx.GlobalString("database-url"))
+	}
+
+	group := &sqlmigr.Group{
+		Concurrency:     ctx.Int("concurrency"),
+		ContinueOnError: ctx.Bool("continue-on-error"),
+	}
+
+	for _, url := range urls {
+		db, err := openURL(url)
+		if err != nil {
+			return nil, err
+		}
+
+		m.dbs = append(m.dbs, db)
+
+		schemas, err := m.schemas(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, schema := range schemas {
+			name := passwordRgxp.ReplaceAllString(url, "$1:***@")
+
+			fields := log.Map{
+				"command":  ctx.Command.Name,
+				"database": name,
+			}
+
+			if schema != "" {
+				fields["schema"] = schema
+				name = fmt.Sprintf("%s (%s)", name, schema)
+			}
+
+			executor := m.newExecutor(db, schema, log.WithFields(fields))
+
+			if dir := ctx.String("backup-dir"); dir != "" {
+				dir = filepath.Join(dir, strings.Map(sanitize, name))
+
+				if executor.Backup, err = m.backup(db, dir); err != nil {
+					return nil, err
+				}
+			}
+
+			group.Targets = append(group.Targets, &sqlmigr.Target{
+				Name:     name,
+				Executor: executor,
+			})
+		}
+	}
+
+	return group, nil
+}
+
+func (m *SQLMigration) grouped(ctx *cli.Context) bool {
+	if len(ctx.StringSlice("database-urls")) > 0 || ctx.String("database-url-file") != "" {
+		return true
+	}
+
+	return m.multischema(ctx)
+}
+
+func (m *SQLMigration) multischema(ctx *cli.Context) bool {
+	return len(ctx.StringSlice("schema")) > 0 || ctx.String("all-schemas") != ""
+}
+
+func (m *SQLMigration) schemas(ctx *cli.Context, db *sqlx.DB) ([]string, error) {
+	if !m.multischema(ctx) {
+		return []string{""}, nil
+	}
+
+	if driver := db.DriverName(); driver != "postgres" {
+		err := fmt.Errorf("Schema is not supported by database driver '%s'", driver)
+		return nil, cli.NewExitError(err.Error(), ErrCodeArg)
+	}
+
+	schemas := ctx.StringSlice("schema")
+
+	if pattern := ctx.String("all-schemas"); pattern != "" {
+		matched, err := sqlmigr.Schemas(db, pattern)
+		if err != nil {
+			return nil, cli.NewExitError(err.Error(), ErrCodeMigration)
+		}
+
+		schemas = append(schemas, matched...)
+	}
+
+	unique := []string{}
+	visited := map[string]bool{}
+
+	// a schema can be both provided and matched by the pattern
+	for _, schema := range schemas {
+		if !visited[schema] {
+			visited[schema] = true
+			unique = append(unique, schema)
+		}
+	}
+
+	return unique, nil
+}
+
+func (m *SQLMigration) urls(ctx *cli.Context) ([]string, error) {
+	urls := ctx.StringSlice("database-urls")
+
+	path := ctx.String("database-url-file")
+	if path == "" {
+		return urls, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, cli.NewExitError(err.Error(), ErrCodeArg)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		urls = append(urls, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, cli.NewExitError(err.Error(), ErrCodeArg)
+	}
+
+	return urls, nil
+}
+
+func (m *SQLMigration) results(ctx *cli.Context, results []*sqlmigr.Result) error {
+	switch {
+	case strings.EqualFold("json", ctx.GlobalString("log-format")):
+		logger := log.WithField("command", ctx.Command.Name)
+		sqlmigr.FlogResults(logger, results)
+	case ctx.Command.Name == "status":
+		sqlmigr.FtableStatus(os.Stdout, results)
+	default:
+		sqlmigr.FtableResults(os.Stdout, results)
+	}
+
+	if failed := sqlmigr.Failed(results); len(failed) > 0 {
+		err := fmt.Errorf("The command failed for %d of %d databases", len(failed), len(results))
+		return cli.NewExitError(err.Error(), ErrCodeMigration)
+	}
+
+	return nil
+}
+
+func sanitize(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+		return r
+	default:
+		return '_'
+	}
+}
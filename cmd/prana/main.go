@@ -39,10 +39,12 @@ var flags = []cli.Flag{
 
 func main() {
 	var (
-		migration  = &cmd.SQLMigration{}
+		migration  = &cmd.SQLMigration{Version: version}
 		routine    = &cmd.SQLRoutine{}
 		model      = &cmd.SQLModel{}
 		repository = &cmd.SQLRepository{}
+		console    = &cmd.SQLConsole{}
+		data       = &cmd.SQLData{}
 	)
 
 	commands := []*cli.Command{
@@ -50,6 +52,8 @@ func main() {
 		routine.CreateCommand(),
 		model.CreateCommand(),
 		repository.CreateCommand(),
+		console.CreateCommand(),
+		data.CreateCommand(),
 	}
 
 	app := &cli.App{
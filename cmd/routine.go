@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -16,6 +19,7 @@ import (
 type SQLRoutine struct {
 	runner   *sqlexec.Runner
 	executor *sqlmodel.Executor
+	provider sqlmodel.SchemaProvider
 }
 
 // CreateCommand creates a cli.Command that can be used by cli.App.
@@ -78,7 +82,113 @@ func (m *SQLRoutine) CreateCommand() *cli.Command {
 				Flags: []cli.Flag{
 					&cli.StringSliceFlag{
 						Name:  "param, p",
-						Usage: "Parameters for the command",
+						Usage: "Parameters for the command in the form of [name][:type]=value or [name]:null",
+					},
+					&cli.StringFlag{
+						Name:  "schema-name, s",
+						Usage: "name of the database schema used to infer the parameter types",
+					},
+					&cli.BoolFlag{
+						Name:  "exec, e",
+						Usage: "execute the command as a statement that does not return rows",
+					},
+					&cli.StringFlag{
+						Name:  "format, f",
+						Usage: "format of the output (table, json, ndjson, csv, tsv, yaml, markdown)",
+						Value: sqlexec.FormatTable,
+					},
+					&cli.StringFlag{
+						Name:  "binary",
+						Usage: "encoding of the binary values (text, hex, base64)",
+						Value: sqlexec.BinaryText,
+					},
+				},
+			},
+			&cli.Command{
+				Name:        "explain",
+				Usage:       "Explain the execution plan of a SQL command for given arguments",
+				Description: "Explain the execution plan of a SQL command for given arguments",
+				ArgsUsage:   "[name]",
+				Action:      m.explain,
+				Before:      m.before,
+				After:       m.after,
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "param, p",
+						Usage: "Parameters for the command in the form of [name][:type]=value or [name]:null",
+					},
+					&cli.StringFlag{
+						Name:  "schema-name, s",
+						Usage: "name of the database schema used to infer the parameter types",
+					},
+					&cli.BoolFlag{
+						Name:  "analyze, a",
+						Usage: "execute the command in a transaction that is rolled back to analyze the plan",
+					},
+					&cli.StringFlag{
+						Name:  "format, f",
+						Usage: "format of the output (table, json, ndjson, csv, tsv, yaml, markdown)",
+						Value: sqlexec.FormatTable,
+					},
+				},
+			},
+			&cli.Command{
+				Name:        "test",
+				Usage:       "Run the test cases of the SQL commands and compare their results with the golden files",
+				Description: "Run the test cases of the SQL commands and compare their results with the golden files",
+				ArgsUsage:   "[name...]",
+				Action:      m.test,
+				Before:      m.before,
+				After:       m.after,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:   "fixture-dir, x",
+						Usage:  "path to the directory that contain the SQL scripts that seed the database",
+						EnvVar: "PRANA_FIXTURE_DIR",
+						Value:  "./database/fixture",
+					},
+					&cli.BoolFlag{
+						Name:  "update, u",
+						Usage: "rewrite the golden files with the actual results",
+					},
+				},
+			},
+			&cli.Command{
+				Name:        "check",
+				Usage:       "Check the SQL commands against the database without their execution",
+				Description: "Check the SQL commands against the database without their execution",
+				ArgsUsage:   "[name...]",
+				Action:      m.check,
+				Before:      m.before,
+				After:       m.after,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "apply-schema",
+						Usage: "apply the schema changes of the commands in a transaction that is rolled back (SQLite and PostgreSQL)",
+					},
+				},
+			},
+			&cli.Command{
+				Name:        "codegen",
+				Usage:       "Generate a package of typed Golang functions for the SQL commands",
+				Description: "Generate a package of typed Golang functions for the SQL commands",
+				Action:      m.codegen,
+				Before:      m.before,
+				After:       m.after,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "package-dir, p",
+						Usage: "path to the package, where the source code will be generated",
+						Value: "./database/routine",
+					},
+					&cli.StringFlag{
+						Name:  "filename, n",
+						Usage: "name of the file that contains the generated source code",
+						Value: "routine.go",
+					},
+					&cli.StringFlag{
+						Name:  "schema-name, s",
+						Usage: "name of the database schema used to infer the parameter types",
 					},
 				},
 			},
@@ -130,6 +240,8 @@ func (m *SQLRoutine) before(ctx *cli.Context) error {
 		return err
 	}
 
+	m.provider = provider
+
 	m.runner = &sqlexec.Runner{
 		FileSystem: storage.New(dir),
 		DB:         db,
@@ -180,24 +292,218 @@ func (m *SQLRoutine) create(ctx *cli.Context) error {
 
 func (m *SQLRoutine) run(ctx *cli.Context) error {
 	args := ctx.Args
-	params := params(ctx.StringSlice("param"))
 
 	if len(args) != 1 {
 		return cli.NewExitError("Run command expects a single argument", ErrCodeCommand)
 	}
 
 	name := args[0]
+
+	params, err := m.params(ctx, name)
+	if err != nil {
+		return err
+	}
+
 	log.Infof("Running command '%s' from '%v'", name, m.runner.FileSystem)
 
+	exec, err := m.exec(ctx, name)
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeCommand)
+	}
+
+	printer := &sqlexec.Printer{
+		Format: ctx.String("format"),
+		Binary: ctx.String("binary"),
+	}
+
+	if exec {
+		results, err := m.runner.Exec(name, params...)
+		if err != nil {
+			return m.failure(name, err)
+		}
+
+		if err := printer.PrintResults(os.Stdout, results); err != nil {
+			return cli.NewExitError(err.Error(), ErrCodeCommand)
+		}
+
+		return nil
+	}
+
 	rows, err := m.runner.Run(name, params...)
+	if err != nil {
+		return m.failure(name, err)
+	}
+
+	if err := printer.Print(os.Stdout, rows); err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeCommand)
+	}
+
+	return nil
+}
+
+func (m *SQLRoutine) explain(ctx *cli.Context) error {
+	args := ctx.Args
+
+	if len(args) != 1 {
+		return cli.NewExitError("Explain command expects a single argument", ErrCodeCommand)
+	}
+
+	name := args[0]
+
+	params, err := m.params(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Explaining command '%s' from '%v'", name, m.runner.FileSystem)
+
+	printer := &sqlexec.Printer{
+		Format: ctx.String("format"),
+	}
+
+	if err := m.runner.Explain(os.Stdout, printer, name, ctx.Bool("analyze"), params...); err != nil {
+		return m.failure(name, err)
+	}
+
+	return nil
+}
+
+func (m *SQLRoutine) test(ctx *cli.Context) error {
+	dir, err := filepath.Abs(ctx.String("fixture-dir"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeArg)
+	}
+
+	tester := &sqlexec.Tester{
+		FileSystem: m.runner.FileSystem.(*storage.FileSystem),
+		Fixtures:   os.DirFS(dir),
+		DB:         m.runner.DB,
+		Update:     ctx.Bool("update"),
+	}
+
+	results, err := tester.Test(ctx.Args...)
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeCommand)
+	}
+
+	failures := 0
+
+	for _, result := range results {
+		switch {
+		case result.Updated:
+			log.Infof("Updated golden file '%s' of command '%s'", result.Path, result.Routine.Name)
+		case result.Err == nil:
+			log.Infof("Passed command '%s' with golden file '%s'", result.Routine.Name, result.Path)
+		default:
+			failures++
+
+			log.WithError(m.failure(result.Routine.Name, result.Err)).Errorf("Failed command '%s' with golden file '%s'", result.Routine.Name, result.Path)
+
+			if result.Expected != nil {
+				fmt.Fprintf(os.Stdout, "--- expected: %s\n%s\n", result.Path, result.Expected)
+				fmt.Fprintf(os.Stdout, "+++ actual: %s\n%s\n", result.Routine.Name, result.Actual)
+			}
+		}
+	}
+
+	if failures > 0 {
+		err = fmt.Errorf("%d of %d test cases failed", failures, len(results))
+		return cli.NewExitError(err.Error(), ErrCodeCommand)
+	}
+
+	log.Infof("Ran %d test cases", len(results))
+	return nil
+}
+
+func (m *SQLRoutine) check(ctx *cli.Context) error {
+	checker := &sqlexec.Checker{
+		FileSystem:  m.runner.FileSystem,
+		DB:          m.runner.DB,
+		ApplySchema: ctx.Bool("apply-schema"),
+	}
+
+	errs, err := checker.Check(ctx.Args...)
 	if err != nil {
 		return cli.NewExitError(err.Error(), ErrCodeCommand)
 	}
 
-	if err := m.runner.Print(os.Stdout, rows); err != nil {
+	dir := ctx.GlobalString("routine-dir")
+
+	for _, err := range errs {
+		path := filepath.Join(dir, err.Routine.File)
+		fmt.Fprintf(os.Stdout, "%s:%d: routine '%s': %v\n", path, err.Line, err.Routine.Name, err.Err)
+	}
+
+	if len(errs) > 0 {
+		err = fmt.Errorf("Found %d problems in the commands", len(errs))
 		return cli.NewExitError(err.Error(), ErrCodeCommand)
 	}
 
+	log.Infof("Checked the commands from '%v'", m.runner.FileSystem)
+	return nil
+}
+
+// failure returns an error that refers to the location of the command
+func (m *SQLRoutine) failure(name string, err error) error {
+	if routine, rerr := m.runner.Routine(name); rerr == nil && routine.File != "" {
+		err = fmt.Errorf("%v (%s:%d)", err, routine.File, routine.StartLine)
+	}
+
+	return cli.NewExitError(err.Error(), ErrCodeCommand)
+}
+
+func (m *SQLRoutine) exec(ctx *cli.Context, name string) (bool, error) {
+	if ctx.Bool("exec") {
+		return true, nil
+	}
+
+	routine, err := m.runner.Routine(name)
+	if err != nil {
+		return false, err
+	}
+
+	return !sqlexec.ReturnsRows(routine), nil
+}
+
+func (m *SQLRoutine) codegen(ctx *cli.Context) error {
+	routines, err := m.runner.Routines()
+	if err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeCommand)
+	}
+
+	var (
+		dir     = ctx.String("package-dir")
+		skipped = 0
+	)
+
+	generator := &sqlmodel.RoutineCodegen{
+		DB:       m.runner.DB,
+		Provider: m.provider,
+		Schema:   ctx.String("schema-name"),
+		Package:  filepath.Base(dir),
+		Skip: func(routine *sqlexec.Routine, reason error) {
+			log.WithError(reason).Warnf("Skipped command '%s'", routine.Name)
+			skipped++
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+
+	if err := generator.Generate(buffer, routines); err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeSchema)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeSchema)
+	}
+
+	path := filepath.Join(dir, ctx.String("filename"))
+
+	if err := ioutil.WriteFile(path, buffer.Bytes(), 0644); err != nil {
+		return cli.NewExitError(err.Error(), ErrCodeSchema)
+	}
+
+	log.Infof("Generated %d typed functions at: '%s'", len(routines)-skipped, path)
 	return nil
 }
 
@@ -245,10 +551,58 @@ func (m *SQLRoutine) spec(ctx *cli.Context) *sqlmodel.Spec {
 	return spec
 }
 
-func params(args []string) []interface{} {
-	result := []interface{}{}
+// params returns the positional parameters or a single map of the named
+// parameters. The type of the untyped named parameters is inferred from the
+// columns of the command's tables when possible.
+func (m *SQLRoutine) params(ctx *cli.Context, name string) ([]interface{}, error) {
+	args := []*sqlexec.Arg{}
+
+	for _, text := range ctx.StringSlice("param") {
+		arg, err := sqlexec.ParseArg(text)
+		if err != nil {
+			return nil, cli.NewExitError(err.Error(), ErrCodeArg)
+		}
+
+		args = append(args, arg)
+	}
+
+	types := m.types(ctx, name, args)
+
+	for _, arg := range args {
+		if arg.Type == "" && arg.Name != "" {
+			arg.Type = types[arg.Name]
+		}
+	}
+
+	params, err := sqlexec.Args(args)
+	if err != nil {
+		return nil, cli.NewExitError(err.Error(), ErrCodeArg)
+	}
+
+	return params, nil
+}
+
+func (m *SQLRoutine) types(ctx *cli.Context, name string, args []*sqlexec.Arg) map[string]string {
+	types := map[string]string{}
+	untyped := false
+
 	for _, arg := range args {
-		result = append(result, arg)
+		untyped = untyped || (arg.Type == "" && arg.Name != "")
+	}
+
+	if !untyped {
+		return types
 	}
-	return result
+
+	query, err := m.runner.Query(name)
+	if err != nil {
+		return types
+	}
+
+	types, err = sqlmodel.ParamTypes(m.provider, ctx.String("schema-name"), query)
+	if err != nil {
+		log.WithError(err).Debugf("Cannot infer the parameter types of command '%s'", name)
+	}
+
+	return types
 }
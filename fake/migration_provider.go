@@ -30,6 +30,18 @@ type MigrationProvider struct {
 	existsReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	HistoryStub        func() ([]*sqlmigr.Event, error)
+	historyMutex       sync.RWMutex
+	historyArgsForCall []struct {
+	}
+	historyReturns struct {
+		result1 []*sqlmigr.Event
+		result2 error
+	}
+	historyReturnsOnCall map[int]struct {
+		result1 []*sqlmigr.Event
+		result2 error
+	}
 	InsertStub        func(*sqlmigr.Migration) error
 	insertMutex       sync.RWMutex
 	insertArgsForCall []struct {
@@ -177,6 +189,61 @@ func (fake *MigrationProvider) ExistsReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *MigrationProvider) History() ([]*sqlmigr.Event, error) {
+	fake.historyMutex.Lock()
+	ret, specificReturn := fake.historyReturnsOnCall[len(fake.historyArgsForCall)]
+	fake.historyArgsForCall = append(fake.historyArgsForCall, struct {
+	}{})
+	fake.recordInvocation("History", []interface{}{})
+	fake.historyMutex.Unlock()
+	if fake.HistoryStub != nil {
+		return fake.HistoryStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.historyReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *MigrationProvider) HistoryCallCount() int {
+	fake.historyMutex.RLock()
+	defer fake.historyMutex.RUnlock()
+	return len(fake.historyArgsForCall)
+}
+
+func (fake *MigrationProvider) HistoryCalls(stub func() ([]*sqlmigr.Event, error)) {
+	fake.historyMutex.Lock()
+	defer fake.historyMutex.Unlock()
+	fake.HistoryStub = stub
+}
+
+func (fake *MigrationProvider) HistoryReturns(result1 []*sqlmigr.Event, result2 error) {
+	fake.historyMutex.Lock()
+	defer fake.historyMutex.Unlock()
+	fake.HistoryStub = nil
+	fake.historyReturns = struct {
+		result1 []*sqlmigr.Event
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *MigrationProvider) HistoryReturnsOnCall(i int, result1 []*sqlmigr.Event, result2 error) {
+	fake.historyMutex.Lock()
+	defer fake.historyMutex.Unlock()
+	fake.HistoryStub = nil
+	if fake.historyReturnsOnCall == nil {
+		fake.historyReturnsOnCall = make(map[int]struct {
+			result1 []*sqlmigr.Event
+			result2 error
+		})
+	}
+	fake.historyReturnsOnCall[i] = struct {
+		result1 []*sqlmigr.Event
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *MigrationProvider) Insert(arg1 *sqlmigr.Migration) error {
 	fake.insertMutex.Lock()
 	ret, specificReturn := fake.insertReturnsOnCall[len(fake.insertArgsForCall)]
@@ -299,6 +366,8 @@ func (fake *MigrationProvider) Invocations() map[string][][]interface{} {
 	defer fake.deleteMutex.RUnlock()
 	fake.existsMutex.RLock()
 	defer fake.existsMutex.RUnlock()
+	fake.historyMutex.RLock()
+	defer fake.historyMutex.RUnlock()
 	fake.insertMutex.RLock()
 	defer fake.insertMutex.RUnlock()
 	fake.migrationsMutex.RLock()
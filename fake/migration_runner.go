@@ -8,6 +8,19 @@ import (
 )
 
 type MigrationRunner struct {
+	PlanStub        func(*sqlmigr.Migration) (*sqlmigr.Plan, error)
+	planMutex       sync.RWMutex
+	planArgsForCall []struct {
+		arg1 *sqlmigr.Migration
+	}
+	planReturns struct {
+		result1 *sqlmigr.Plan
+		result2 error
+	}
+	planReturnsOnCall map[int]struct {
+		result1 *sqlmigr.Plan
+		result2 error
+	}
 	RevertStub        func(*sqlmigr.Migration) error
 	revertMutex       sync.RWMutex
 	revertArgsForCall []struct {
@@ -34,6 +47,69 @@ type MigrationRunner struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *MigrationRunner) Plan(arg1 *sqlmigr.Migration) (*sqlmigr.Plan, error) {
+	fake.planMutex.Lock()
+	ret, specificReturn := fake.planReturnsOnCall[len(fake.planArgsForCall)]
+	fake.planArgsForCall = append(fake.planArgsForCall, struct {
+		arg1 *sqlmigr.Migration
+	}{arg1})
+	fake.recordInvocation("Plan", []interface{}{arg1})
+	fake.planMutex.Unlock()
+	if fake.PlanStub != nil {
+		return fake.PlanStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.planReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *MigrationRunner) PlanCallCount() int {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	return len(fake.planArgsForCall)
+}
+
+func (fake *MigrationRunner) PlanCalls(stub func(*sqlmigr.Migration) (*sqlmigr.Plan, error)) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = stub
+}
+
+func (fake *MigrationRunner) PlanArgsForCall(i int) *sqlmigr.Migration {
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
+	argsForCall := fake.planArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *MigrationRunner) PlanReturns(result1 *sqlmigr.Plan, result2 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	fake.planReturns = struct {
+		result1 *sqlmigr.Plan
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *MigrationRunner) PlanReturnsOnCall(i int, result1 *sqlmigr.Plan, result2 error) {
+	fake.planMutex.Lock()
+	defer fake.planMutex.Unlock()
+	fake.PlanStub = nil
+	if fake.planReturnsOnCall == nil {
+		fake.planReturnsOnCall = make(map[int]struct {
+			result1 *sqlmigr.Plan
+			result2 error
+		})
+	}
+	fake.planReturnsOnCall[i] = struct {
+		result1 *sqlmigr.Plan
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *MigrationRunner) Revert(arg1 *sqlmigr.Migration) error {
 	fake.revertMutex.Lock()
 	ret, specificReturn := fake.revertReturnsOnCall[len(fake.revertArgsForCall)]
@@ -157,6 +233,8 @@ func (fake *MigrationRunner) RunReturnsOnCall(i int, result1 error) {
 func (fake *MigrationRunner) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.planMutex.RLock()
+	defer fake.planMutex.RUnlock()
 	fake.revertMutex.RLock()
 	defer fake.revertMutex.RUnlock()
 	fake.runMutex.RLock()
@@ -4,6 +4,7 @@ go 1.25.8
 
 require (
 	github.com/aymerick/raymond v2.0.2+incompatible
+	github.com/chzyer/readline v1.5.1
 	github.com/fatih/color v1.19.0
 	github.com/go-openapi/inflect v0.21.6
 	github.com/go-sql-driver/mysql v1.10.0
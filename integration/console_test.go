@@ -0,0 +1,70 @@
+package integration_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Console", func() {
+	var cmd *exec.Cmd
+
+	JustBeforeEach(func() {
+		dir, err := ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		script := &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: show-migration")
+		fmt.Fprintln(script, "SELECT description FROM migrations WHERE id = :id;")
+
+		Expect(os.MkdirAll(filepath.Join(dir, "/database/routine"), 0700)).To(Succeed())
+		path := filepath.Join(dir, "/database/routine/20060102150405.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		cmd = exec.Command(gomPath, append(args, "console", "--format", "ndjson", "--history-file", filepath.Join(dir, "history"))...)
+		cmd.Dir = dir
+	})
+
+	It("runs the statements and the routines", func() {
+		input := &bytes.Buffer{}
+		fmt.Fprintln(input, "SELECT id")
+		fmt.Fprintln(input, "FROM migrations;")
+		fmt.Fprintln(input, `\r show-migration id=00060524000000`)
+		fmt.Fprintln(input, `\q`)
+
+		cmd.Stdin = input
+
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Out).To(gbytes.Say(`\{"id":"00060524000000"\}`))
+		Expect(session.Out).To(gbytes.Say(`\{"description":"setup"\}`))
+	})
+
+	Context("when the statement fails", func() {
+		It("reports the error", func() {
+			cmd.Stdin = strings.NewReader("SELECT * FROM unknown;\n")
+
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			Expect(session.Out).To(gbytes.Say("ERROR: no such table: unknown"))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Data Copy", func() {
+	var (
+		cmd *exec.Cmd
+		dir string
+	)
+
+	JustBeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		cmd = exec.Command(gomPath, append(args, "data", "copy", "--to", "sqlite3://copy.db")...)
+		cmd.Dir = dir
+	})
+
+	It("copies the tables successfully", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Err).To(gbytes.Say("Copied 1 rows of table 'migrations'"))
+		Expect(session.Err).To(gbytes.Say("Verified 1 rows of table 'migrations'"))
+		Expect(session.Err).To(gbytes.Say("Copied 1 tables successfully"))
+
+		db, err := sqlx.Open("sqlite3", filepath.Join(dir, "copy.db"))
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+
+		count := 0
+		Expect(db.Get(&count, "SELECT COUNT(*) FROM migrations")).To(Succeed())
+		Expect(count).To(Equal(1))
+	})
+
+	Context("when the target table is not empty", func() {
+		JustBeforeEach(func() {
+			first := exec.Command(cmd.Path, cmd.Args[1:]...)
+			first.Dir = dir
+
+			session, err := gexec.Start(first, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+		})
+
+		It("returns an error", func() {
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(106))
+
+			Expect(session.Err).To(gbytes.Say("table 'migrations': the target table has 1 rows"))
+		})
+
+		It("replaces the rows when the truncate is enabled", func() {
+			cmd.Args = append(cmd.Args, "--truncate")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			Expect(session.Err).To(gbytes.Say("Verified 1 rows of table 'migrations'"))
+		})
+	})
+
+	Context("when the table does not exist", func() {
+		It("returns an error", func() {
+			cmd.Args = append(cmd.Args, "--table", "users")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(106))
+
+			Expect(session.Err).To(gbytes.Say("table 'users' not found"))
+		})
+	})
+
+	Context("when the target is not provided", func() {
+		It("returns an error", func() {
+			cmd.Args = cmd.Args[:len(cmd.Args)-2]
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(101))
+
+			Expect(session.Err).To(gbytes.Say("The target database URL is not provided"))
+		})
+	})
+})
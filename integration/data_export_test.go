@@ -0,0 +1,68 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Data Export", func() {
+	var (
+		cmd *exec.Cmd
+		dir string
+	)
+
+	JustBeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		cmd = exec.Command(gomPath, append(args, "data", "export", "--table", "migrations")...)
+		cmd.Dir = dir
+	})
+
+	It("exports the rows successfully", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Out).To(gbytes.Say("id,description"))
+		Expect(session.Out).To(gbytes.Say("00060524000000,setup"))
+		Expect(session.Err).To(gbytes.Say("Exported 1 rows from table 'migrations' successfully"))
+	})
+
+	Context("when the output is provided", func() {
+		It("writes the rows to the file", func() {
+			cmd.Args = append(cmd.Args, "--format", "json", "--output", "migrations.json")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			data, err := ioutil.ReadFile(filepath.Join(dir, "migrations.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"id":"00060524000000","description":"setup"`))
+		})
+	})
+
+	Context("when the table does not exist", func() {
+		It("returns an error", func() {
+			cmd.Args = append(cmd.Args[:len(cmd.Args)-1], "users")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(106))
+
+			Expect(session.Err).To(gbytes.Say("table 'users' not found"))
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package integration_test
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Data Import", func() {
+	var (
+		cmd *exec.Cmd
+		db  *sql.DB
+	)
+
+	JustBeforeEach(func() {
+		dir, err := ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		db, err = sql.Open("sqlite3", filepath.Join(dir, "gom.db"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+		Expect(err).NotTo(HaveOccurred())
+
+		cmd = exec.Command(gomPath, append(args, "data", "import", "--table", "users")...)
+		cmd.Dir = dir
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	It("imports the rows successfully", func() {
+		cmd.Stdin = strings.NewReader("id,name\n1,jack\n2,peter\n")
+
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Err).To(gbytes.Say("Imported 2 rows into table 'users' successfully"))
+
+		count := 0
+		Expect(db.QueryRow("SELECT count(*) FROM users").Scan(&count)).To(Succeed())
+		Expect(count).To(Equal(2))
+	})
+
+	Context("when a row is invalid", func() {
+		It("does not import any row", func() {
+			cmd.Stdin = strings.NewReader("id,name\n1,jack\nfirst,peter\n")
+
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(106))
+
+			count := -1
+			Expect(db.QueryRow("SELECT count(*) FROM users").Scan(&count)).To(Succeed())
+			Expect(count).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package integration_test
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migration Run With Backup", func() {
+	var (
+		cmd *exec.Cmd
+		db  *sql.DB
+		dir string
+	)
+
+	JustBeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		args = append(args, "migration")
+
+		script := &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: up")
+		fmt.Fprintln(script, "CREATE TABLE users(id INT);")
+		fmt.Fprintln(script, "-- name: down")
+		fmt.Fprintln(script, "DROP TABLE users;")
+
+		path := filepath.Join(dir, "/database/migration/20060102150405_schema.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		script = &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: up")
+		fmt.Fprintln(script, "INSERT INTO documents VALUES(1);")
+		fmt.Fprintln(script, "-- name: down")
+		fmt.Fprintln(script, "DELETE FROM documents;")
+
+		path = filepath.Join(dir, "/database/migration/20070102150405_data.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		cmd = exec.Command(gomPath, append(args, "run", "--backup-dir", "backup")...)
+		cmd.Dir = dir
+
+		db, err = sql.Open("sqlite3", filepath.Join(dir, "gom.db"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	It("restores the database when the migration fails", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(-1))
+
+		_, err = db.Exec("SELECT * FROM users")
+		Expect(err).To(MatchError("no such table: users"))
+
+		row := db.QueryRow("SELECT count(*) FROM migrations")
+
+		count := 0
+		Expect(row.Scan(&count)).To(Succeed())
+		Expect(count).To(Equal(1))
+
+		files, err := filepath.Glob(filepath.Join(dir, "backup", "*.db"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,173 @@
+package integration_test
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migration Run Against Multiple Databases", func() {
+	var (
+		cmd *exec.Cmd
+		dir string
+	)
+
+	count := func(name string) int {
+		db, err := sql.Open("sqlite3", filepath.Join(dir, name))
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+
+		count := 0
+		Expect(db.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&count)).To(Succeed())
+		return count
+	}
+
+	JustBeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		args = append(args, "migration")
+
+		script := &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: up")
+		fmt.Fprintln(script, "SELECT * FROM migrations;")
+		fmt.Fprintln(script, "-- name: down")
+		fmt.Fprintln(script, "SELECT * FROM migrations;")
+
+		path := filepath.Join(dir, "/database/migration/20060102150405_schema.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		urls := &bytes.Buffer{}
+		fmt.Fprintln(urls, "# tenants")
+		fmt.Fprintln(urls, "sqlite3://two.db")
+		fmt.Fprintln(urls)
+		fmt.Fprintln(urls, "sqlite3://three.db")
+
+		path = filepath.Join(dir, "databases.txt")
+		Expect(ioutil.WriteFile(path, urls.Bytes(), 0700)).To(Succeed())
+
+		cmd = exec.Command(gomPath, append(args, "run",
+			"--database-urls", "sqlite3://one.db",
+			"--database-url-file", "databases.txt",
+			"--concurrency", "2")...)
+		cmd.Dir = dir
+	})
+
+	It("runs the migrations on each database", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Out).To(gbytes.Say("sqlite3://one.db"))
+		Expect(count("one.db")).To(Equal(2))
+		Expect(count("two.db")).To(Equal(2))
+		Expect(count("three.db")).To(Equal(2))
+	})
+
+	Context("when the actor is provided", func() {
+		It("records the actor in the history of each database", func() {
+			cmd.Args = append(cmd.Args[:len(cmd.Args)-7], "--actor", "ci", "run", "--database-urls", "sqlite3://one.db")
+
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			db, err := sql.Open("sqlite3", filepath.Join(dir, "one.db"))
+			Expect(err).NotTo(HaveOccurred())
+			defer db.Close()
+
+			actors := 0
+			Expect(db.QueryRow("SELECT COUNT(*) FROM migrations_history WHERE actor = 'ci'").Scan(&actors)).To(Succeed())
+			Expect(actors).To(Equal(2))
+		})
+	})
+
+	Context("when the database url is provided by the environment", func() {
+		It("runs the migrations on the database", func() {
+			env := append(os.Environ(), "PRANA_DB_URL=sqlite3://one.db")
+
+			for _, command := range []string{"setup", "run"} {
+				cmd = exec.Command(gomPath, "migration", command)
+				cmd.Dir = dir
+				cmd.Env = env
+
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(0))
+			}
+
+			Expect(count("one.db")).To(Equal(2))
+		})
+	})
+
+	Context("when the status is requested", func() {
+		It("shows the status of each database", func() {
+			cmd.Args = append(cmd.Args[:len(cmd.Args)-7], "status", "--database-urls", "sqlite3://gom.db")
+
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			Expect(session.Out).To(gbytes.Say("Executed"))
+			Expect(session.Out).To(gbytes.Say("sqlite3://gom.db"))
+		})
+	})
+
+	Context("when the schema is provided", func() {
+		It("returns an error", func() {
+			cmd.Args = append(cmd.Args, "--schema", "tenant_a")
+
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(101))
+			Expect(session.Err).To(gbytes.Say("Schema is not supported by database driver 'sqlite3'"))
+		})
+	})
+
+	Context("when a database fails", func() {
+		JustBeforeEach(func() {
+			script := &bytes.Buffer{}
+			fmt.Fprintln(script, "-- name: up")
+			fmt.Fprintln(script, "SELECT * FROM documents;")
+			fmt.Fprintln(script, "-- name: down")
+			fmt.Fprintln(script, "SELECT * FROM documents;")
+
+			path := filepath.Join(dir, "/database/migration/20070102150405_documents.sql")
+			Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+			db, err := sql.Open("sqlite3", filepath.Join(dir, "one.db"))
+			Expect(err).NotTo(HaveOccurred())
+			defer db.Close()
+
+			_, err = db.Exec("CREATE TABLE documents(id INT)")
+			Expect(err).NotTo(HaveOccurred())
+
+			cmd.Args = append(cmd.Args[:len(cmd.Args)-2], "--continue-on-error")
+		})
+
+		It("returns an error", func() {
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(103))
+
+			Expect(session.Err).To(gbytes.Say("The command failed for 2 of 3 databases"))
+			Expect(count("one.db")).To(Equal(3))
+		})
+	})
+})
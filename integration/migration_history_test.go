@@ -0,0 +1,81 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migration History", func() {
+	var (
+		cmd  *exec.Cmd
+		args []string
+	)
+
+	JustBeforeEach(func() {
+		dir, err := ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args = []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		cmd = exec.Command(gomPath, append(args, "migration", "--actor", "ci", "history")...)
+		cmd.Dir = dir
+	})
+
+	It("returns the migration history successfully", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Out).To(gbytes.Say("00060524000000"))
+		Expect(session.Out).To(gbytes.Say("run"))
+	})
+
+	Context("when the migration is reverted", func() {
+		JustBeforeEach(func() {
+			script := []byte("-- name: up\nSELECT 1;\n-- name: down\nSELECT 1;\n")
+			path := filepath.Join(cmd.Dir, "database", "migration", "20060102150405_schema.sql")
+			Expect(ioutil.WriteFile(path, script, 0700)).To(Succeed())
+
+			for _, operation := range []string{"run", "revert"} {
+				migration := exec.Command(gomPath, append(args, "migration", "--actor", "ci", operation)...)
+				migration.Dir = cmd.Dir
+
+				session, err := gexec.Start(migration, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(0))
+			}
+		})
+
+		It("keeps the revert event", func() {
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			Expect(session.Out).To(gbytes.Say("20060102150405"))
+			Expect(session.Out).To(gbytes.Say("run"))
+			Expect(session.Out).To(gbytes.Say("20060102150405"))
+			Expect(session.Out).To(gbytes.Say("revert"))
+			Expect(session.Out).To(gbytes.Say("ci"))
+		})
+	})
+
+	Context("when the database is not available", func() {
+		It("returns an empty history", func() {
+			Expect(os.Remove(cmd.Dir + "/gom.db")).To(Succeed())
+
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+		})
+	})
+})
@@ -0,0 +1,58 @@
+package integration_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migration Plan", func() {
+	var cmd *exec.Cmd
+
+	JustBeforeEach(func() {
+		dir, err := ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		args = append(args, "migration")
+
+		script := &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: up")
+		fmt.Fprintln(script, "CREATE TABLE users(id INT);")
+		fmt.Fprintln(script, "GO")
+		fmt.Fprintln(script, "INSERT INTO users VALUES(1);")
+		fmt.Fprintln(script, "-- name: down")
+		fmt.Fprintln(script, "DROP TABLE users;")
+
+		path := filepath.Join(dir, "/database/migration/20060102150405_schema.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		cmd = exec.Command(gomPath, append(args, "plan", "--include-sql")...)
+		cmd.Dir = dir
+	})
+
+	It("returns the plan of the pending migrations", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Out).To(gbytes.Say("20060102150405"))
+		Expect(session.Out).To(gbytes.Say("20060102150405_schema.sql"))
+		Expect(session.Out).To(gbytes.Say("2"))
+		Expect(session.Out).To(gbytes.Say("yes"))
+		Expect(session.Out).To(gbytes.Say("CREATE TABLE users"))
+		Expect(session.Out).To(gbytes.Say("INSERT INTO users"))
+		Expect(string(session.Out.Contents())).NotTo(ContainSubstring("00060524000000"))
+	})
+})
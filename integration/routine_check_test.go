@@ -0,0 +1,70 @@
+package integration_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Script Check", func() {
+	var (
+		cmd    *exec.Cmd
+		script *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		script = &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: show-migration")
+		fmt.Fprintln(script, "SELECT * FROM migrations WHERE id = :id;")
+	})
+
+	JustBeforeEach(func() {
+		dir, err := ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		Expect(os.MkdirAll(filepath.Join(dir, "/database/routine"), 0700)).To(Succeed())
+		path := filepath.Join(dir, "/database/routine/20060102150405.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		cmd = exec.Command(gomPath, append(args, "routine", "check")...)
+		cmd.Dir = dir
+	})
+
+	It("checks the commands successfully", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Err).To(gbytes.Say("Checked the commands"))
+	})
+
+	Context("when a command is broken", func() {
+		BeforeEach(func() {
+			fmt.Fprintln(script)
+			fmt.Fprintln(script, "-- name: show-users")
+			fmt.Fprintln(script, "SELECT * FROM users;")
+		})
+
+		It("reports the problem", func() {
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(104))
+
+			Expect(session.Out).To(gbytes.Say(`database/routine/20060102150405.sql:5: routine 'show-users': no such table: users`))
+			Expect(session.Err).To(gbytes.Say("Found 1 problems in the commands"))
+		})
+	})
+})
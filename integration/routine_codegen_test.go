@@ -0,0 +1,63 @@
+package integration_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Script Codegen", func() {
+	var cmd *exec.Cmd
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		script := &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: select-migration-by-id")
+		fmt.Fprintln(script, "-- returns: one")
+		fmt.Fprintln(script, "SELECT id, description FROM migrations WHERE id = :id;")
+		fmt.Fprintln(script)
+		fmt.Fprintln(script, "-- name: delete-migration")
+		fmt.Fprintln(script, "DELETE FROM migrations WHERE id = ?;")
+
+		Expect(os.MkdirAll(filepath.Join(dir, "/database/routine"), 0700)).To(Succeed())
+		path := filepath.Join(dir, "/database/routine/20060102150405.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		cmd = exec.Command(gomPath, append(args, "routine", "codegen")...)
+		cmd.Dir = dir
+	})
+
+	It("generates the typed functions successfully", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Err).To(gbytes.Say("Generated 2 typed functions"))
+
+		path := filepath.Join(cmd.Dir, "/database/routine/routine.go")
+		Expect(path).To(BeARegularFile())
+
+		data, err := ioutil.ReadFile(path)
+		Expect(err).To(BeNil())
+
+		source := string(data)
+		Expect(source).To(ContainSubstring("package routine"))
+		Expect(source).To(ContainSubstring("func SelectMigrationByID(ctx context.Context, db sqlx.ExtContext, id string) (*SelectMigrationByIDRow, error)"))
+		Expect(source).To(ContainSubstring("func DeleteMigration(ctx context.Context, db sqlx.ExtContext, arg1 interface{}) (sql.Result, error)"))
+	})
+})
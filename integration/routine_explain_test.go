@@ -0,0 +1,72 @@
+package integration_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Script Explain", func() {
+	var cmd *exec.Cmd
+
+	JustBeforeEach(func() {
+		dir, err := ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		script := &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: show-migration")
+		fmt.Fprintln(script, "SELECT * FROM migrations WHERE id = :id;")
+
+		Expect(os.MkdirAll(filepath.Join(dir, "/database/routine"), 0700)).To(Succeed())
+		path := filepath.Join(dir, "/database/routine/20060102150405.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		cmd = exec.Command(gomPath, append(args, "routine", "explain")...)
+		cmd.Dir = dir
+	})
+
+	It("explains the command successfully", func() {
+		cmd.Args = append(cmd.Args, "show-migration", "--param", "id=00060524000000", "--format", "csv")
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Err).To(gbytes.Say("Explaining command 'show-migration'"))
+		Expect(session.Out).To(gbytes.Say("id,parent,notused,detail"))
+	})
+
+	Context("when the plan should be analyzed", func() {
+		It("returns an error", func() {
+			cmd.Args = append(cmd.Args, "show-migration", "--param", "id=00060524000000", "--analyze")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(104))
+
+			Expect(session.Err).To(gbytes.Say("does not support EXPLAIN ANALYZE"))
+		})
+	})
+
+	Context("when the command does not exist", func() {
+		It("returns an error", func() {
+			cmd.Args = append(cmd.Args, "unknown")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(104))
+
+			Expect(session.Err).To(gbytes.Say("query 'unknown' not found"))
+		})
+	})
+})
@@ -58,6 +58,93 @@ var _ = Describe("Script Run", func() {
 		Expect(session.Err).To(gbytes.Say("Running command 'show-migrations'"))
 	})
 
+	Context("when the format is provided", func() {
+		It("prints the rows in the format", func() {
+			cmd.Args = append(cmd.Args, "show-migrations", "--format", "ndjson")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			Expect(session.Out).To(gbytes.Say(`\{"id":"00060524000000","description":"setup"`))
+		})
+	})
+
+	Context("when the command has named parameters", func() {
+		JustBeforeEach(func() {
+			script := &bytes.Buffer{}
+			fmt.Fprintln(script, "-- name: show-migration")
+			fmt.Fprintln(script, "SELECT * FROM migrations WHERE id = :id AND description = :description;")
+
+			path := filepath.Join(cmd.Dir, "/database/routine/20070102150405.sql")
+			Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+		})
+
+		It("runs command successfully", func() {
+			cmd.Args = append(cmd.Args, "show-migration", "--param", "id=00060524000000", "--param", "description=setup")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+
+			Expect(session.Out).To(gbytes.Say("00060524000000"))
+		})
+
+		Context("when the parameters are typed", func() {
+			It("runs command successfully", func() {
+				cmd.Args = append(cmd.Args, "show-migration", "--param", "id:string=00060524000000", "--param", "description:null")
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(0))
+
+				Expect(session.Out).NotTo(gbytes.Say("00060524000000"))
+			})
+		})
+
+		Context("when the parameter value does not match its type", func() {
+			It("returns an error", func() {
+				cmd.Args = append(cmd.Args, "show-migration", "--param", "id:int=first", "--param", "description=setup")
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(101))
+				Expect(session.Err).To(gbytes.Say("invalid parameter 'id'"))
+			})
+		})
+
+		Context("when named and positional parameters are mixed", func() {
+			It("returns an error", func() {
+				cmd.Args = append(cmd.Args, "show-migration", "--param", "id=00060524000000", "--param", "setup")
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(101))
+				Expect(session.Err).To(gbytes.Say("cannot mix named and positional parameters"))
+			})
+		})
+	})
+
+	Context("when the command does not return rows", func() {
+		JustBeforeEach(func() {
+			script := &bytes.Buffer{}
+			fmt.Fprintln(script, "-- name: rename-migration")
+			fmt.Fprintln(script, "UPDATE migrations SET description = ? WHERE id = ?;")
+			fmt.Fprintln(script, "GO")
+			fmt.Fprintln(script, "DELETE FROM migrations WHERE id = ?;")
+
+			path := filepath.Join(cmd.Dir, "/database/routine/20080102150405.sql")
+			Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+		})
+
+		It("executes the command successfully", func() {
+			cmd.Args = append(cmd.Args, "rename-migration", "--param", "init", "--param", "00060524000000", "--param", "20060102150405")
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("ROWS AFFECTED"))
+
+			description := ""
+			Expect(db.QueryRow("SELECT description FROM migrations").Scan(&description)).To(Succeed())
+			Expect(description).To(Equal("init"))
+		})
+	})
+
 	Context("when the database is not available", func() {
 		It("returns an error", func() {
 			Expect(os.Remove(filepath.Join(cmd.Dir, "gom.db"))).To(Succeed())
@@ -0,0 +1,85 @@
+package integration_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Script Test", func() {
+	var (
+		cmd *exec.Cmd
+		dir string
+	)
+
+	JustBeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "gom")
+		Expect(err).To(BeNil())
+
+		args := []string{"--database-url", "sqlite3://gom.db"}
+
+		Setup(args, dir)
+
+		script := &bytes.Buffer{}
+		fmt.Fprintln(script, "-- name: show-migration")
+		fmt.Fprintln(script, "-- test: params=id=00060524000000 expect=golden/show-migration.csv")
+		fmt.Fprintln(script, "SELECT id, description FROM migrations WHERE id = :id;")
+
+		Expect(os.MkdirAll(filepath.Join(dir, "/database/routine/golden"), 0700)).To(Succeed())
+		path := filepath.Join(dir, "/database/routine/20060102150405.sql")
+		Expect(ioutil.WriteFile(path, script.Bytes(), 0700)).To(Succeed())
+
+		path = filepath.Join(dir, "/database/routine/golden/show-migration.csv")
+		Expect(ioutil.WriteFile(path, []byte("id,description\n00060524000000,setup\n"), 0700)).To(Succeed())
+
+		cmd = exec.Command(gomPath, append(args, "routine", "test")...)
+		cmd.Dir = dir
+	})
+
+	It("runs the test cases successfully", func() {
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit(0))
+
+		Expect(session.Err).To(gbytes.Say("Passed command 'show-migration' with golden file 'golden/show-migration.csv'"))
+	})
+
+	Context("when the result does not match the golden file", func() {
+		JustBeforeEach(func() {
+			path := filepath.Join(dir, "/database/routine/golden/show-migration.csv")
+			Expect(ioutil.WriteFile(path, []byte("id,description\n00060524000000,init\n"), 0700)).To(Succeed())
+		})
+
+		It("returns an error", func() {
+			session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(session).Should(gexec.Exit(104))
+
+			Expect(session.Err).To(gbytes.Say("1 of 1 test cases failed"))
+		})
+
+		Context("when the update flag is provided", func() {
+			It("rewrites the golden file", func() {
+				cmd.Args = append(cmd.Args, "--update")
+				session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(session).Should(gexec.Exit(0))
+
+				data, err := ioutil.ReadFile(filepath.Join(dir, "/database/routine/golden/show-migration.csv"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(Equal("id,description\n00060524000000,setup\n"))
+			})
+		})
+	})
+})
@@ -0,0 +1,464 @@
+package sqldata
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlmigr"
+	"github.com/phogolabs/prana/sqlmodel"
+)
+
+var (
+	castRgxp   = regexp.MustCompile(`::[A-Za-z ]+(\(\d+(,\s*\d+)?\))?(\[\])?$`)
+	nowRgxp    = regexp.MustCompile(`^(CURRENT_TIMESTAMP|CURRENT_DATE|LOCALTIMESTAMP|NOW)(\(\d*\))?$|^DATETIME\('NOW'\)$`)
+	numberRgxp = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	stringRgxp = regexp.MustCompile(`^'([^']|'')*'$`)
+)
+
+// CopyResult represents the result of a copied table.
+type CopyResult struct {
+	// Table is the name of the copied table
+	Table string
+	// SourceCount is the number of the rows in the source table
+	SourceCount int64
+	// TargetCount is the number of the rows in the target table
+	TargetCount int64
+}
+
+// Copier copies the tables between databases of different dialects. The
+// tables are created in the target database with column types compatible
+// with the source columns. The auto increment columns and the portable
+// default values (literals and the current time) are kept. The rows are read
+// in a read-only transaction and copied in batches. The values are converted
+// to the types of the target columns (e.g. booleans, timestamps and binary
+// values). The rows of each table are inserted in a single transaction, which
+// is committed only if the row counts of the source and the target table
+// match. The table is created in the same transaction if the target database
+// supports the transactional schema changes (SQLite and PostgreSQL) or it's
+// dropped if the copy fails otherwise. The existing target tables must be
+// empty unless they are truncated.
+type Copier struct {
+	// Source is a client to the source database.
+	Source *sqlx.DB
+	// Target is a client to the target database.
+	Target *sqlx.DB
+	// Provider provides the schema of the source database.
+	Provider sqlmodel.SchemaProvider
+	// Schema is the name of the source database schema.
+	Schema string
+	// Tables are the names of the copied tables. All tables are copied if
+	// they are not provided.
+	Tables []string
+	// Truncate deletes the rows of the existing target tables in the copy
+	// transaction. The copy of a table fails if the target table has rows
+	// otherwise.
+	Truncate bool
+	// BatchSize is the number of rows copied at once. Default to 1000.
+	BatchSize int
+	// Progress is called after each batch.
+	Progress func(table string, count int64)
+}
+
+// Copy copies the tables and returns their row counts. It stops at the first
+// table that fails (e.g. the row counts do not match) and returns the results
+// of the tables copied before.
+func (c *Copier) Copy() ([]*CopyResult, error) {
+	names := c.Tables
+
+	if len(names) == 0 {
+		tables, err := c.Provider.Tables(c.Schema)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range tables {
+			// the internal tables of SQLite cannot be copied
+			if !strings.HasPrefix(name, "sqlite_") {
+				names = append(names, name)
+			}
+		}
+	}
+
+	results := []*CopyResult{}
+
+	for _, name := range names {
+		result, err := c.copy(name)
+		if err != nil {
+			return results, fmt.Errorf("table '%s': %v", name, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (c *Copier) copy(name string) (*CopyResult, error) {
+	table, err := table(c.Provider, c.Schema, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		exporter = &Exporter{DB: c.Source, Schema: c.Schema}
+		importer = &Importer{DB: c.Target}
+		names    = []string{}
+		kinds    = []string{}
+		result   = &CopyResult{Table: name}
+	)
+
+	for index := range table.Columns {
+		column := &table.Columns[index]
+		names = append(names, column.Name)
+		kinds = append(kinds, kind(column))
+	}
+
+	size := importer.batch(len(names))
+
+	if c.BatchSize > 0 && c.BatchSize < size {
+		size = c.BatchSize
+	}
+
+	source, err := exporter.begin()
+	if err != nil {
+		return nil, err
+	}
+
+	// the transaction only reads the rows
+	defer source.Rollback()
+
+	// the count and the rows are read from the same snapshot
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", qualify(c.Source.DriverName(), c.Schema, name))
+
+	if err := source.Get(&result.SourceCount, query); err != nil {
+		return nil, err
+	}
+
+	created, err := c.prepareTable(table)
+	if err != nil {
+		return nil, err
+	}
+
+	fail := func(err error) (*CopyResult, error) {
+		if created {
+			return nil, c.drop(name, err)
+		}
+
+		return nil, err
+	}
+
+	tx, err := c.Target.Beginx()
+	if err != nil {
+		return fail(err)
+	}
+
+	if c.transactional() {
+		// the table is dropped by the rollback if the copy fails
+		if _, err := tx.Exec(c.create(table)); err != nil {
+			return fail(importer.rollback(tx, err))
+		}
+	}
+
+	if err := c.prepare(tx, name); err != nil {
+		return fail(importer.rollback(tx, err))
+	}
+
+	_, err = exporter.read(source, exporter.query(table), size, kinds, func(records [][]interface{}, count int64) error {
+		if err := importer.insert(tx, table, names, records); err != nil {
+			return err
+		}
+
+		if c.Progress != nil {
+			c.Progress(name, count)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fail(importer.rollback(tx, err))
+	}
+
+	if err := c.sequences(tx, table); err != nil {
+		return fail(importer.rollback(tx, err))
+	}
+
+	query = fmt.Sprintf("SELECT COUNT(*) FROM %s", quote(c.Target.DriverName(), name))
+
+	if err := tx.Get(&result.TargetCount, query); err != nil {
+		return fail(importer.rollback(tx, err))
+	}
+
+	if result.SourceCount != result.TargetCount {
+		err := fmt.Errorf("%d rows in the source but %d rows in the target", result.SourceCount, result.TargetCount)
+		return fail(importer.rollback(tx, err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fail(err)
+	}
+
+	return result, nil
+}
+
+// transactional returns true if the target database can roll back the
+// schema changes
+func (c *Copier) transactional() bool {
+	switch c.Target.DriverName() {
+	case "sqlite3", "postgres":
+		return true
+	default:
+		return false
+	}
+}
+
+// prepareTable creates the target table before the copy transaction if the
+// target database cannot roll back the schema changes (e.g. MySQL). It
+// returns true if the table is created, so it must be dropped if the copy
+// fails.
+func (c *Copier) prepareTable(table *sqlmodel.Table) (bool, error) {
+	if c.transactional() {
+		return false, nil
+	}
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE 1 = 0", quote(c.Target.DriverName(), table.Name))
+	count := 0
+
+	switch err := c.Target.Get(&count, query); {
+	case err == nil:
+		return false, nil
+	case !sqlmigr.IsNotExist(err):
+		return false, err
+	}
+
+	if _, err := c.Target.Exec(c.create(table)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// drop drops the target table that is created by the failed copy
+func (c *Copier) drop(name string, err error) error {
+	query := fmt.Sprintf("DROP TABLE %s", quote(c.Target.DriverName(), name))
+
+	if _, xerr := c.Target.Exec(query); xerr != nil {
+		return fmt.Errorf("%v: drop table failure: %v", err, xerr)
+	}
+
+	return err
+}
+
+// prepare truncates the target table or checks that it's empty
+func (c *Copier) prepare(tx *sqlx.Tx, name string) error {
+	table := quote(c.Target.DriverName(), name)
+
+	if c.Truncate {
+		// DELETE is rolled back with the transaction unlike TRUNCATE
+		_, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", table))
+		return err
+	}
+
+	count := int64(0)
+
+	if err := tx.Get(&count, fmt.Sprintf("SELECT COUNT(*) FROM %s", table)); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return fmt.Errorf("the target table has %d rows. Use truncate to replace them", count)
+	}
+
+	return nil
+}
+
+// sequences moves the PostgreSQL sequences of the auto increment columns
+// after the copied values. MySQL and SQLite do it on insert.
+func (c *Copier) sequences(tx *sqlx.Tx, table *sqlmodel.Table) error {
+	dialect := c.Target.DriverName()
+
+	if dialect != "postgres" {
+		return nil
+	}
+
+	for index := range table.Columns {
+		column := &table.Columns[index]
+
+		if !column.Type.IsAutoIncrement || kind(column) != kindInt {
+			continue
+		}
+
+		var (
+			name  = quote(dialect, table.Name)
+			field = quote(dialect, column.Name)
+		)
+
+		query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence($1, $2), MAX(%s)) FROM %s HAVING MAX(%s) IS NOT NULL", field, name, field)
+
+		if _, err := tx.Exec(query, name, column.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// create returns the statement that creates the table in the target database
+func (c *Copier) create(table *sqlmodel.Table) string {
+	var (
+		dialect = c.Target.DriverName()
+		items   = []string{}
+		keys    = []string{}
+	)
+
+	for index := range table.Columns {
+		column := &table.Columns[index]
+		definition := quote(dialect, column.Name) + " " + c.columnType(dialect, column)
+
+		if !column.Type.IsNullable {
+			definition = definition + " NOT NULL"
+		}
+
+		if column.Type.IsAutoIncrement && kind(column) == kindInt {
+			switch dialect {
+			case "postgres":
+				definition = definition + " GENERATED BY DEFAULT AS IDENTITY"
+			case "mysql":
+				definition = definition + " AUTO_INCREMENT"
+			}
+		} else if value := c.defaultValue(column); value != "" {
+			definition = definition + " DEFAULT " + value
+		}
+
+		if column.Type.IsPrimaryKey {
+			keys = append(keys, quote(dialect, column.Name))
+		}
+
+		items = append(items, definition)
+	}
+
+	if len(keys) > 0 {
+		items = append(items, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keys, ", ")))
+	}
+
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n  %s\n)", quote(dialect, table.Name), strings.Join(items, ",\n  "))
+}
+
+// defaultValue returns the default value of the target column. Only the
+// literals and the current time are portable between the dialects, so the
+// other expressions (e.g. functions) are not copied.
+func (c *Copier) defaultValue(column *sqlmodel.Column) string {
+	value := strings.TrimSpace(column.Type.Default)
+
+	// the casts of PostgreSQL (e.g. 'draft'::character varying)
+	value = castRgxp.ReplaceAllString(value, "")
+
+	// the expressions of SQLite and MySQL (e.g. (0) or (now()))
+	for strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+
+	var (
+		category = kind(column)
+		upper    = strings.ToUpper(value)
+	)
+
+	switch {
+	case value == "" || upper == "NULL":
+		return ""
+	case nowRgxp.MatchString(upper):
+		switch {
+		case category != kindTime:
+			return ""
+		case typeName(column) == "date":
+			return "CURRENT_DATE"
+		default:
+			return "CURRENT_TIMESTAMP"
+		}
+	case category == kindBool:
+		switch strings.Trim(upper, "'") {
+		case "1", "TRUE", "T", "YES", "Y":
+			return "TRUE"
+		case "0", "FALSE", "F", "NO", "N":
+			return "FALSE"
+		default:
+			return ""
+		}
+	case numberRgxp.MatchString(value) || stringRgxp.MatchString(value):
+		return value
+	default:
+		return ""
+	}
+}
+
+// columnType returns the type of the target column that is compatible with
+// the source column
+func (c *Copier) columnType(dialect string, column *sqlmodel.Column) string {
+	var (
+		spec = column.Type
+		name = typeName(column)
+	)
+
+	switch kind(column) {
+	case kindBool:
+		return "BOOLEAN"
+	case kindInt:
+		if dialect == "sqlite3" {
+			return "INTEGER"
+		}
+
+		return "BIGINT"
+	case kindFloat:
+		switch {
+		case dialect == "postgres":
+			return "DOUBLE PRECISION"
+		case dialect == "mysql":
+			return "DOUBLE"
+		default:
+			return "REAL"
+		}
+	case kindTime:
+		switch {
+		case name == "date":
+			return "DATE"
+		case dialect == "postgres":
+			return "TIMESTAMP WITH TIME ZONE"
+		case dialect == "mysql":
+			return "DATETIME(6)"
+		default:
+			return "DATETIME"
+		}
+	case kindBinary:
+		switch dialect {
+		case "postgres":
+			return "BYTEA"
+		case "mysql":
+			return "LONGBLOB"
+		default:
+			return "BLOB"
+		}
+	default:
+		switch {
+		case (name == "numeric" || name == "decimal") && spec.Precision > 0:
+			return fmt.Sprintf("NUMERIC(%d, %d)", spec.Precision, spec.PrecisionScale)
+		case name == "numeric" || name == "decimal":
+			if dialect == "mysql" {
+				// the default scale of MySQL is zero
+				return "DECIMAL(65, 30)"
+			}
+
+			return "NUMERIC"
+		case name == "money" && dialect == "postgres":
+			return "MONEY"
+		case spec.CharMaxLength > 0:
+			return fmt.Sprintf("VARCHAR(%d)", spec.CharMaxLength)
+		case dialect == "mysql" && spec.IsPrimaryKey:
+			// the keys of MySQL cannot be TEXT
+			return "VARCHAR(255)"
+		default:
+			return "TEXT"
+		}
+	}
+}
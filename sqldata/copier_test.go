@@ -0,0 +1,258 @@
+package sqldata_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqldata"
+	"github.com/phogolabs/prana/sqlmodel"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Copier", func() {
+	type User struct {
+		ID        int64     `db:"id"`
+		Name      string    `db:"name"`
+		Active    bool      `db:"active"`
+		CreatedAt time.Time `db:"created_at"`
+		Avatar    []byte    `db:"avatar"`
+	}
+
+	var copier *sqldata.Copier
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "prana_copier")
+		Expect(err).To(BeNil())
+
+		source, err := sqlx.Open("sqlite3", filepath.Join(dir, "source.db"))
+		Expect(err).To(BeNil())
+
+		target, err := sqlx.Open("sqlite3", filepath.Join(dir, "target.db"))
+		Expect(err).To(BeNil())
+
+		_, err = source.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name VARCHAR(64) NOT NULL, active BOOLEAN, created_at DATETIME, avatar BLOB)")
+		Expect(err).To(BeNil())
+
+		_, err = source.Exec("INSERT INTO users (name, active, created_at, avatar) VALUES ('jack', 1, '2006-01-02 15:04:05', X'CAFE'), ('peter', 0, '2007-01-02 15:04:05', X'BEEF'), ('john', 1, '2008-01-02 15:04:05', X'')")
+		Expect(err).To(BeNil())
+
+		_, err = source.Exec("CREATE TABLE roles (name TEXT, score NUMERIC(5, 2))")
+		Expect(err).To(BeNil())
+
+		copier = &sqldata.Copier{
+			Source:    source,
+			Target:    target,
+			Provider:  &sqlmodel.SQLiteProvider{DB: source},
+			BatchSize: 2,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(copier.Source.Close()).To(Succeed())
+		Expect(copier.Target.Close()).To(Succeed())
+	})
+
+	It("copies the tables successfully", func() {
+		progress := []int64{}
+
+		copier.Progress = func(table string, count int64) {
+			if table == "users" {
+				progress = append(progress, count)
+			}
+		}
+
+		results, err := copier.Copy()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Equal([]*sqldata.CopyResult{
+			{Table: "roles", SourceCount: 0, TargetCount: 0},
+			{Table: "users", SourceCount: 3, TargetCount: 3},
+		}))
+		Expect(progress).To(Equal([]int64{2, 3}))
+
+		users := []User{}
+		Expect(copier.Target.Select(&users, "SELECT * FROM users ORDER BY id")).To(Succeed())
+		Expect(users).To(HaveLen(3))
+		Expect(users[0].Name).To(Equal("jack"))
+		Expect(users[0].Active).To(BeTrue())
+		Expect(users[0].CreatedAt.Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC))).To(BeTrue())
+		Expect(users[0].Avatar).To(Equal([]byte{0xca, 0xfe}))
+		Expect(users[1].Active).To(BeFalse())
+	})
+
+	It("creates the tables with compatible columns", func() {
+		copier.Tables = []string{"users"}
+
+		_, err := copier.Copy()
+		Expect(err).NotTo(HaveOccurred())
+
+		query := ""
+		Expect(copier.Target.Get(&query, "SELECT sql FROM sqlite_master WHERE name = 'users'")).To(Succeed())
+		Expect(query).To(Equal("CREATE TABLE \"users\" (\n" +
+			"  \"id\" INTEGER,\n" +
+			"  \"name\" VARCHAR(64) NOT NULL,\n" +
+			"  \"active\" BOOLEAN,\n" +
+			"  \"created_at\" DATETIME,\n" +
+			"  \"avatar\" BLOB,\n" +
+			"  PRIMARY KEY (\"id\")\n" +
+			")"))
+	})
+
+	Context("when the columns are interval and numeric", func() {
+		BeforeEach(func() {
+			_, err := copier.Source.Exec("CREATE TABLE ledger (duration INTERVAL, amount NUMERIC(30,10))")
+			Expect(err).To(BeNil())
+		})
+
+		It("creates the columns that keep the values", func() {
+			copier.Tables = []string{"ledger"}
+
+			_, err := copier.Copy()
+			Expect(err).NotTo(HaveOccurred())
+
+			query := ""
+			Expect(copier.Target.Get(&query, "SELECT sql FROM sqlite_master WHERE name = 'ledger'")).To(Succeed())
+			Expect(query).To(Equal("CREATE TABLE \"ledger\" (\n" +
+				"  \"duration\" TEXT,\n" +
+				"  \"amount\" NUMERIC(30, 10)\n" +
+				")"))
+		})
+	})
+
+	Context("when the columns have default values", func() {
+		BeforeEach(func() {
+			_, err := copier.Source.Exec("CREATE TABLE settings (id INTEGER PRIMARY KEY, name TEXT NOT NULL DEFAULT 'it''s', enabled BOOLEAN DEFAULT 1, retries INTEGER DEFAULT (-1), created_at DATETIME DEFAULT CURRENT_TIMESTAMP, seed REAL DEFAULT (random()))")
+			Expect(err).To(BeNil())
+		})
+
+		It("creates the columns with the portable default values", func() {
+			copier.Tables = []string{"settings"}
+
+			_, err := copier.Copy()
+			Expect(err).NotTo(HaveOccurred())
+
+			query := ""
+			Expect(copier.Target.Get(&query, "SELECT sql FROM sqlite_master WHERE name = 'settings'")).To(Succeed())
+			Expect(query).To(Equal("CREATE TABLE \"settings\" (\n" +
+				"  \"id\" INTEGER,\n" +
+				"  \"name\" TEXT NOT NULL DEFAULT 'it''s',\n" +
+				"  \"enabled\" BOOLEAN DEFAULT TRUE,\n" +
+				"  \"retries\" INTEGER DEFAULT -1,\n" +
+				"  \"created_at\" DATETIME DEFAULT CURRENT_TIMESTAMP,\n" +
+				"  \"seed\" REAL,\n" +
+				"  PRIMARY KEY (\"id\")\n" +
+				")"))
+
+			_, err = copier.Target.Exec("INSERT INTO settings DEFAULT VALUES")
+			Expect(err).To(BeNil())
+
+			row := struct {
+				ID      int64  `db:"id"`
+				Name    string `db:"name"`
+				Enabled bool   `db:"enabled"`
+			}{}
+
+			Expect(copier.Target.Get(&row, "SELECT id, name, enabled FROM settings")).To(Succeed())
+			Expect(row.ID).To(Equal(int64(1)))
+			Expect(row.Name).To(Equal("it's"))
+			Expect(row.Enabled).To(BeTrue())
+		})
+	})
+
+	Context("when the rows cannot be read", func() {
+		BeforeEach(func() {
+			_, err := copier.Source.Exec("CREATE TABLE documents (id INTEGER PRIMARY KEY, body TEXT)")
+			Expect(err).To(BeNil())
+
+			_, err = copier.Source.Exec("INSERT INTO documents (id, body) VALUES (1, '{}'), (2, '{'), (3, '{}')")
+			Expect(err).To(BeNil())
+
+			// the second row fails when it's read
+			_, err = copier.Source.Exec("CREATE VIEW broken AS SELECT id, json(body) AS body FROM documents")
+			Expect(err).To(BeNil())
+		})
+
+		It("does not leave the created table behind", func() {
+			copier.Tables = []string{"broken"}
+			copier.BatchSize = 1
+
+			_, err := copier.Copy()
+			Expect(err).To(MatchError(ContainSubstring("malformed JSON")))
+
+			count := 0
+			Expect(copier.Target.Get(&count, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'broken'")).To(Succeed())
+			Expect(count).To(BeZero())
+		})
+	})
+
+	Context("when the target table is not empty", func() {
+		BeforeEach(func() {
+			_, err := copier.Target.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, active BOOLEAN, created_at DATETIME, avatar BLOB)")
+			Expect(err).To(BeNil())
+
+			_, err = copier.Target.Exec("INSERT INTO users (id, name) VALUES (100, 'john')")
+			Expect(err).To(BeNil())
+		})
+
+		It("returns an error", func() {
+			results, err := copier.Copy()
+			Expect(err).To(MatchError("table 'users': the target table has 1 rows. Use truncate to replace them"))
+			Expect(results).To(HaveLen(1))
+
+			count := 0
+			Expect(copier.Target.Get(&count, "SELECT COUNT(*) FROM users")).To(Succeed())
+			Expect(count).To(Equal(1))
+		})
+
+		Context("when the truncate is enabled", func() {
+			BeforeEach(func() {
+				copier.Truncate = true
+			})
+
+			It("replaces the rows", func() {
+				results, err := copier.Copy()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results).To(ContainElement(&sqldata.CopyResult{Table: "users", SourceCount: 3, TargetCount: 3}))
+
+				names := []string{}
+				Expect(copier.Target.Select(&names, "SELECT name FROM users ORDER BY id")).To(Succeed())
+				Expect(names).To(Equal([]string{"jack", "peter", "john"}))
+			})
+		})
+	})
+
+	Context("when the row counts do not match", func() {
+		BeforeEach(func() {
+			// the trigger duplicates the copied rows
+			_, err := copier.Target.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, active BOOLEAN, created_at DATETIME, avatar BLOB)")
+			Expect(err).To(BeNil())
+
+			_, err = copier.Target.Exec("CREATE TRIGGER duplicate AFTER INSERT ON users WHEN NEW.id < 100 BEGIN INSERT INTO users (id, name) VALUES (NEW.id + 100, NEW.name); END")
+			Expect(err).To(BeNil())
+		})
+
+		It("rollbacks the copy", func() {
+			copier.Tables = []string{"users"}
+
+			results, err := copier.Copy()
+			Expect(err).To(MatchError("table 'users': 3 rows in the source but 6 rows in the target"))
+			Expect(results).To(BeEmpty())
+
+			count := 0
+			Expect(copier.Target.Get(&count, "SELECT COUNT(*) FROM users")).To(Succeed())
+			Expect(count).To(BeZero())
+		})
+	})
+
+	Context("when the table does not exist", func() {
+		It("returns an error", func() {
+			copier.Tables = []string{"unknown"}
+
+			_, err := copier.Copy()
+			Expect(err).To(MatchError("table 'unknown': table 'unknown' not found"))
+		})
+	})
+})
@@ -0,0 +1,340 @@
+package sqldata
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlmodel"
+)
+
+// Exporter exports the rows of a table in batches. The rows are read by a
+// single query in a read-only transaction, so they are a consistent snapshot
+// of the table. The rows are ordered by the primary key.
+type Exporter struct {
+	// DB is a client to underlying database.
+	DB *sqlx.DB
+	// Provider provides the table schema.
+	Provider sqlmodel.SchemaProvider
+	// Schema is the name of the database schema.
+	Schema string
+	// Format of the output (csv, json or sql). Default to csv.
+	Format string
+	// BatchSize is the number of rows read at once. Default to 1000.
+	BatchSize int
+	// Progress is called after each batch.
+	Progress Progress
+}
+
+// Export writes the rows of a given table and returns their count.
+func (e *Exporter) Export(writer io.Writer, name string) (int64, error) {
+	table, err := table(e.Provider, e.Schema, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var encoder encoder
+
+	switch strings.ToLower(e.Format) {
+	case "", FormatCSV:
+		encoder = &csvEncoder{writer: csv.NewWriter(writer)}
+	case FormatJSON:
+		encoder = &jsonEncoder{writer: writer}
+	case FormatSQL:
+		encoder = &sqlEncoder{writer: writer, dialect: e.DB.DriverName(), table: qualify(e.DB.DriverName(), e.Schema, name)}
+	default:
+		return 0, fmt.Errorf("unsupported format '%s'", e.Format)
+	}
+
+	names := []string{}
+	kinds := []string{}
+
+	for index := range table.Columns {
+		column := &table.Columns[index]
+		names = append(names, column.Name)
+		kinds = append(kinds, kind(column))
+	}
+
+	if err := encoder.Begin(names); err != nil {
+		return 0, err
+	}
+
+	tx, err := e.begin()
+	if err != nil {
+		return 0, err
+	}
+
+	// the transaction only reads the rows
+	defer tx.Rollback()
+
+	count, err := e.read(tx, e.query(table), e.batch(), kinds, func(records [][]interface{}, count int64) error {
+		if err := encoder.Encode(records); err != nil {
+			return err
+		}
+
+		if e.Progress != nil {
+			e.Progress(count)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return count, err
+	}
+
+	return count, encoder.End()
+}
+
+// begin starts a read-only transaction that sees a snapshot of the database.
+// The transactions of SQLite are serializable.
+func (e *Exporter) begin() (*sqlx.Tx, error) {
+	opts := &sql.TxOptions{}
+
+	switch e.DB.DriverName() {
+	case "postgres", "mysql":
+		opts.ReadOnly = true
+		opts.Isolation = sql.LevelRepeatableRead
+	}
+
+	return e.DB.BeginTxx(context.Background(), opts)
+}
+
+// query returns the query that selects the rows
+func (e *Exporter) query(table *sqlmodel.Table) string {
+	var (
+		dialect = e.DB.DriverName()
+		names   = []string{}
+		order   = []string{}
+	)
+
+	for _, column := range table.Columns {
+		names = append(names, column.Name)
+
+		if column.Type.IsPrimaryKey {
+			order = append(order, quote(dialect, column.Name))
+		}
+	}
+
+	// the tables without primary key are ordered by all columns
+	if len(order) == 0 {
+		for index := range table.Columns {
+			order = append(order, fmt.Sprintf("%d", index+1))
+		}
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s ORDER BY %s",
+		columns(dialect, names),
+		qualify(dialect, e.Schema, table.Name),
+		strings.Join(order, ", "),
+	)
+}
+
+// read reads the rows of the query and calls the function for every batch
+// with the number of the rows read so far. It returns the number of the rows.
+func (e *Exporter) read(tx *sqlx.Tx, query string, size int, kinds []string, fn func(records [][]interface{}, count int64) error) (int64, error) {
+	rows, err := tx.Queryx(query)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	var (
+		records = [][]interface{}{}
+		count   = int64(0)
+	)
+
+	flush := func() error {
+		if len(records) == 0 {
+			return nil
+		}
+
+		count += int64(len(records))
+
+		if err := fn(records, count); err != nil {
+			return err
+		}
+
+		records = [][]interface{}{}
+		return nil
+	}
+
+	for rows.Next() {
+		record, err := rows.SliceScan()
+		if err != nil {
+			return count, err
+		}
+
+		for index, value := range record {
+			record[index] = normalize(kinds[index], value)
+		}
+
+		if records = append(records, record); len(records) < size {
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return count, err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, flush()
+}
+
+func (e *Exporter) batch() int {
+	if e.BatchSize <= 0 {
+		return DefaultBatchSize
+	}
+
+	return e.BatchSize
+}
+
+type encoder interface {
+	Begin(columns []string) error
+	Encode(records [][]interface{}) error
+	End() error
+}
+
+type csvEncoder struct {
+	writer *csv.Writer
+}
+
+func (e *csvEncoder) Begin(columns []string) error {
+	return e.writer.Write(columns)
+}
+
+func (e *csvEncoder) Encode(records [][]interface{}) error {
+	for _, record := range records {
+		values := make([]string, len(record))
+
+		for index, value := range record {
+			values[index] = text(value)
+		}
+
+		if err := e.writer.Write(values); err != nil {
+			return err
+		}
+	}
+
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvEncoder) End() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type jsonEncoder struct {
+	writer  io.Writer
+	columns []string
+	count   int
+}
+
+func (e *jsonEncoder) Begin(columns []string) error {
+	e.columns = columns
+	_, err := io.WriteString(e.writer, "[")
+	return err
+}
+
+// Encode writes the records as JSON objects that keep the order of the
+// columns
+func (e *jsonEncoder) Encode(records [][]interface{}) error {
+	for _, record := range records {
+		buffer := &strings.Builder{}
+
+		if e.count > 0 {
+			buffer.WriteString(",")
+		}
+
+		buffer.WriteString("\n{")
+
+		for index, column := range e.columns {
+			if index > 0 {
+				buffer.WriteString(",")
+			}
+
+			key, err := marshal(column)
+			if err != nil {
+				return err
+			}
+
+			value, err := marshal(record[index])
+			if err != nil {
+				return err
+			}
+
+			buffer.Write(key)
+			buffer.WriteString(":")
+			buffer.Write(value)
+		}
+
+		buffer.WriteString("}")
+		e.count++
+
+		if _, err := io.WriteString(e.writer, buffer.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *jsonEncoder) End() error {
+	_, err := io.WriteString(e.writer, "\n]\n")
+	return err
+}
+
+type sqlEncoder struct {
+	writer  io.Writer
+	dialect string
+	table   string
+	columns string
+}
+
+func (e *sqlEncoder) Begin(names []string) error {
+	e.columns = columns(e.dialect, names)
+	return nil
+}
+
+// Encode writes the records of the batch as a single INSERT statement
+func (e *sqlEncoder) Encode(records [][]interface{}) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	buffer := &strings.Builder{}
+	fmt.Fprintf(buffer, "INSERT INTO %s (%s) VALUES", e.table, e.columns)
+
+	for index, record := range records {
+		values := make([]string, len(record))
+
+		for position, value := range record {
+			values[position] = literal(e.dialect, value)
+		}
+
+		if index > 0 {
+			buffer.WriteString(",")
+		}
+
+		fmt.Fprintf(buffer, "\n(%s)", strings.Join(values, ", "))
+	}
+
+	buffer.WriteString(";\n")
+
+	_, err := io.WriteString(e.writer, buffer.String())
+	return err
+}
+
+func (e *sqlEncoder) End() error {
+	return nil
+}
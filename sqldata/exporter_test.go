@@ -0,0 +1,185 @@
+package sqldata_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/fake"
+	"github.com/phogolabs/prana/sqldata"
+	"github.com/phogolabs/prana/sqlmodel"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Exporter", func() {
+	var (
+		exporter *sqldata.Exporter
+		buffer   *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "prana_exporter")
+		Expect(err).To(BeNil())
+
+		db, err := sqlx.Open("sqlite3", filepath.Join(dir, "prana.db"))
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, active BOOLEAN, score REAL, created_at DATETIME, avatar BLOB)")
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("INSERT INTO users VALUES (2, 'it''s peter', 0, NULL, NULL, NULL)")
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("INSERT INTO users VALUES (1, 'jack', 1, 4.5, '2006-01-02 15:04:05', X'CAFE')")
+		Expect(err).To(BeNil())
+
+		buffer = &bytes.Buffer{}
+
+		exporter = &sqldata.Exporter{
+			DB:        db,
+			Provider:  &sqlmodel.SQLiteProvider{DB: db},
+			BatchSize: 1,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(exporter.DB.Close()).To(Succeed())
+	})
+
+	It("exports the rows as CSV", func() {
+		count, err := exporter.Export(buffer, "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeEquivalentTo(2))
+		Expect(buffer.String()).To(Equal("id,name,active,score,created_at,avatar\n" +
+			"1,jack,true,4.5,2006-01-02T15:04:05Z,yv4=\n" +
+			"2,it's peter,false,\\N,\\N,\\N\n"))
+	})
+
+	It("exports the rows as JSON", func() {
+		exporter.Format = sqldata.FormatJSON
+
+		_, err := exporter.Export(buffer, "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal("[\n" +
+			`{"id":1,"name":"jack","active":true,"score":4.5,"created_at":"2006-01-02T15:04:05Z","avatar":"yv4="},` + "\n" +
+			`{"id":2,"name":"it's peter","active":false,"score":null,"created_at":null,"avatar":null}` + "\n" +
+			"]\n"))
+	})
+
+	It("exports the rows as SQL", func() {
+		exporter.Format = sqldata.FormatSQL
+		exporter.BatchSize = 10
+
+		_, err := exporter.Export(buffer, "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`INSERT INTO "users" ("id", "name", "active", "score", "created_at", "avatar") VALUES` + "\n" +
+			`(1, 'jack', 1, 4.5, '2006-01-02 15:04:05+00:00', X'cafe'),` + "\n" +
+			`(2, 'it''s peter', 0, NULL, NULL, NULL);` + "\n"))
+	})
+
+	It("reports the progress", func() {
+		progress := []int64{}
+
+		exporter.Progress = func(count int64) {
+			progress = append(progress, count)
+		}
+
+		_, err := exporter.Export(buffer, "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress).To(Equal([]int64{1, 2}))
+	})
+
+	Context("when the rows are deleted during the export", func() {
+		BeforeEach(func() {
+			// the rows can be changed while they are read
+			_, err := exporter.DB.Exec("PRAGMA journal_mode=WAL")
+			Expect(err).To(BeNil())
+		})
+
+		It("exports the rows that existed at the start", func() {
+			exporter.Progress = func(count int64) {
+				if count == 1 {
+					_, err := exporter.DB.Exec("DELETE FROM users WHERE id = 1")
+					Expect(err).To(BeNil())
+				}
+			}
+
+			count, err := exporter.Export(buffer, "users")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(BeEquivalentTo(2))
+			Expect(buffer.String()).To(ContainSubstring("1,jack,"))
+			Expect(buffer.String()).To(ContainSubstring("2,it's peter,"))
+		})
+	})
+
+	Context("when the columns are interval and numeric", func() {
+		BeforeEach(func() {
+			_, err := exporter.DB.Exec("CREATE TABLE ledger (duration TEXT, amount TEXT)")
+			Expect(err).To(BeNil())
+
+			_, err = exporter.DB.Exec("INSERT INTO ledger VALUES ('1 day', '12345678901234567890.1234567890')")
+			Expect(err).To(BeNil())
+
+			// the types of PostgreSQL
+			provider := &fake.SchemaProvider{}
+			provider.SchemaReturns(&sqlmodel.Schema{
+				Tables: []sqlmodel.Table{
+					{
+						Name: "ledger",
+						Columns: []sqlmodel.Column{
+							{Name: "duration", Type: sqlmodel.ColumnType{Name: "interval"}},
+							{Name: "amount", Type: sqlmodel.ColumnType{Name: "numeric", Precision: 30, PrecisionScale: 10}},
+						},
+					},
+				},
+			}, nil)
+
+			exporter.Provider = provider
+		})
+
+		It("exports the values as strings", func() {
+			exporter.Format = sqldata.FormatJSON
+
+			_, err := exporter.Export(buffer, "ledger")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buffer.String()).To(Equal("[\n" +
+				`{"duration":"1 day","amount":"12345678901234567890.1234567890"}` + "\n" +
+				"]\n"))
+		})
+	})
+
+	Context("when the table is empty", func() {
+		BeforeEach(func() {
+			_, err := exporter.DB.Exec("DELETE FROM users")
+			Expect(err).To(BeNil())
+		})
+
+		It("exports an empty array as JSON", func() {
+			exporter.Format = sqldata.FormatJSON
+
+			count, err := exporter.Export(buffer, "users")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(BeZero())
+			Expect(buffer.String()).To(Equal("[\n]\n"))
+		})
+	})
+
+	Context("when the table does not exist", func() {
+		It("returns an error", func() {
+			_, err := exporter.Export(buffer, "roles")
+			Expect(err).To(MatchError("table 'roles' not found"))
+		})
+	})
+
+	Context("when the format is not supported", func() {
+		It("returns an error", func() {
+			exporter.Format = "xml"
+
+			_, err := exporter.Export(buffer, "users")
+			Expect(err).To(MatchError("unsupported format 'xml'"))
+		})
+	})
+})
@@ -0,0 +1,352 @@
+package sqldata
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlexec"
+	"github.com/phogolabs/prana/sqlmodel"
+)
+
+// maxParams is the number of the parameters that every database supports in
+// a single statement
+const maxParams = 999
+
+// Importer imports the rows of a table in batches. The rows are imported in
+// a single transaction, which is rolled back if any of them fails.
+type Importer struct {
+	// DB is a client to underlying database.
+	DB *sqlx.DB
+	// Provider provides the table schema.
+	Provider sqlmodel.SchemaProvider
+	// Schema is the name of the database schema.
+	Schema string
+	// Format of the input (csv, json or sql). Default to csv.
+	Format string
+	// BatchSize is the number of rows inserted at once. Default to 1000.
+	BatchSize int
+	// Progress is called after each batch.
+	Progress Progress
+}
+
+// Import reads the rows of a given table and returns their count. The
+// values are converted to the types of the table columns. The statements of
+// a SQL script are executed as they are.
+func (i *Importer) Import(reader io.Reader, name string) (int64, error) {
+	table, err := table(i.Provider, i.Schema, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var decoder decoder
+
+	switch strings.ToLower(i.Format) {
+	case "", FormatCSV:
+		decoder = &csvDecoder{reader: csv.NewReader(reader)}
+	case FormatJSON:
+		decoder = &jsonDecoder{decoder: json.NewDecoder(reader)}
+	case FormatSQL:
+		return i.script(reader)
+	default:
+		return 0, fmt.Errorf("unsupported format '%s'", i.Format)
+	}
+
+	names, err := decoder.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	kinds := []string{}
+
+	for _, name := range names {
+		column := i.column(table, name)
+
+		if column == nil {
+			return 0, fmt.Errorf("column '%s' does not exist in table '%s'", name, table.Name)
+		}
+
+		kinds = append(kinds, kind(column))
+	}
+
+	tx, err := i.DB.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		size    = i.batch(len(names))
+		records = [][]interface{}{}
+		count   = int64(0)
+	)
+
+	for {
+		record, err := decoder.Decode(kinds)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return 0, i.rollback(tx, fmt.Errorf("row %d: %v", count+int64(len(records))+1, err))
+		}
+
+		if records = append(records, record); len(records) < size {
+			continue
+		}
+
+		if err := i.insert(tx, table, names, records); err != nil {
+			return 0, i.rollback(tx, err)
+		}
+
+		count += int64(len(records))
+		records = [][]interface{}{}
+
+		if i.Progress != nil {
+			i.Progress(count)
+		}
+	}
+
+	if len(records) > 0 {
+		if err := i.insert(tx, table, names, records); err != nil {
+			return 0, i.rollback(tx, err)
+		}
+
+		count += int64(len(records))
+
+		if i.Progress != nil {
+			i.Progress(count)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// script executes the statements of a SQL script
+func (i *Importer) script(reader io.Reader) (int64, error) {
+	splitter := &sqlexec.Splitter{Dialect: i.DB.DriverName()}
+	statements := splitter.Split(reader)
+
+	tx, err := i.DB.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	count := int64(0)
+
+	for index, statement := range statements {
+		result, err := tx.Exec(statement)
+		if err != nil {
+			return 0, i.rollback(tx, fmt.Errorf("statement %d: %v", index+1, err))
+		}
+
+		affected, _ := result.RowsAffected()
+		count += affected
+
+		if i.Progress != nil {
+			i.Progress(count)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// insert inserts the records with a single INSERT statement
+func (i *Importer) insert(tx *sqlx.Tx, table *sqlmodel.Table, names []string, records [][]interface{}) error {
+	var (
+		dialect = i.DB.DriverName()
+		values  = make([]string, len(records))
+		args    = []interface{}{}
+	)
+
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ") + ")"
+
+	for index, record := range records {
+		values[index] = placeholders
+		args = append(args, record...)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		qualify(dialect, i.Schema, table.Name),
+		columns(dialect, names),
+		strings.Join(values, ", "),
+	)
+
+	_, err := tx.Exec(tx.Rebind(query), args...)
+	return err
+}
+
+func (i *Importer) column(table *sqlmodel.Table, name string) *sqlmodel.Column {
+	for index := range table.Columns {
+		if column := &table.Columns[index]; strings.EqualFold(column.Name, name) {
+			return column
+		}
+	}
+
+	return nil
+}
+
+// batch returns the number of the rows in a batch, which is limited by the
+// number of the parameters of a statement
+func (i *Importer) batch(columns int) int {
+	size := i.BatchSize
+
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	if columns > 0 && size*columns > maxParams {
+		size = maxParams / columns
+	}
+
+	if size < 1 {
+		size = 1
+	}
+
+	return size
+}
+
+func (i *Importer) rollback(tx *sqlx.Tx, err error) error {
+	if xerr := tx.Rollback(); xerr != nil {
+		return fmt.Errorf("%v: rollback failure: %v", err, xerr)
+	}
+
+	return err
+}
+
+type decoder interface {
+	Columns() ([]string, error)
+	Decode(kinds []string) ([]interface{}, error)
+}
+
+type csvDecoder struct {
+	reader *csv.Reader
+}
+
+func (d *csvDecoder) Columns() ([]string, error) {
+	return d.reader.Read()
+}
+
+func (d *csvDecoder) Decode(kinds []string) ([]interface{}, error) {
+	values, err := d.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]interface{}, len(values))
+
+	for index, value := range values {
+		if value == csvNull {
+			continue
+		}
+
+		if record[index], err = parse(kinds[index], value); err != nil {
+			return nil, err
+		}
+	}
+
+	return record, nil
+}
+
+// jsonDecoder decodes an array of JSON objects. The columns are the keys of
+// the first object.
+type jsonDecoder struct {
+	decoder *json.Decoder
+	columns []string
+	first   map[string]interface{}
+}
+
+func (d *jsonDecoder) Columns() ([]string, error) {
+	d.decoder.UseNumber()
+
+	token, err := d.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if token != json.Delim('[') {
+		return nil, fmt.Errorf("expected an array of objects")
+	}
+
+	if !d.decoder.More() {
+		return []string{}, nil
+	}
+
+	// the order of the keys is kept by reading the object token by token
+	if token, err = d.decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("expected an array of objects")
+	}
+
+	d.first = map[string]interface{}{}
+
+	for d.decoder.More() {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key := token.(string)
+		value := (interface{})(nil)
+
+		if err := d.decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		d.columns = append(d.columns, key)
+		d.first[key] = value
+	}
+
+	if _, err := d.decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return d.columns, nil
+}
+
+func (d *jsonDecoder) Decode(kinds []string) ([]interface{}, error) {
+	object := d.first
+	d.first = nil
+
+	if object == nil {
+		if !d.decoder.More() {
+			return nil, io.EOF
+		}
+
+		if err := d.decoder.Decode(&object); err != nil {
+			return nil, err
+		}
+	}
+
+	record := make([]interface{}, len(d.columns))
+
+	for index, column := range d.columns {
+		value, err := unmarshal(kinds[index], object[column])
+		if err != nil {
+			return nil, err
+		}
+
+		record[index] = value
+		delete(object, column)
+	}
+
+	for key := range object {
+		return nil, fmt.Errorf("unexpected column '%s'", key)
+	}
+
+	return record, nil
+}
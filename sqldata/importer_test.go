@@ -0,0 +1,190 @@
+package sqldata_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/fake"
+	"github.com/phogolabs/prana/sqldata"
+	"github.com/phogolabs/prana/sqlmodel"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Importer", func() {
+	type User struct {
+		ID        int64      `db:"id"`
+		Name      string     `db:"name"`
+		Active    bool       `db:"active"`
+		Score     *float64   `db:"score"`
+		CreatedAt *time.Time `db:"created_at"`
+		Avatar    []byte     `db:"avatar"`
+	}
+
+	var importer *sqldata.Importer
+
+	users := func() []User {
+		items := []User{}
+		Expect(importer.DB.Select(&items, "SELECT * FROM users ORDER BY id")).To(Succeed())
+		return items
+	}
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "prana_importer")
+		Expect(err).To(BeNil())
+
+		db, err := sqlx.Open("sqlite3", filepath.Join(dir, "prana.db"))
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, active BOOLEAN, score REAL, created_at DATETIME, avatar BLOB)")
+		Expect(err).To(BeNil())
+
+		importer = &sqldata.Importer{
+			DB:       db,
+			Provider: &sqlmodel.SQLiteProvider{DB: db},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(importer.DB.Close()).To(Succeed())
+	})
+
+	It("imports the rows from CSV", func() {
+		input := "id,name,active,score,created_at,avatar\n" +
+			"1,jack,true,4.5,2006-01-02T15:04:05Z,yv4=\n" +
+			"2,peter,false,\\N,\\N,\\N\n"
+
+		count, err := importer.Import(strings.NewReader(input), "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeEquivalentTo(2))
+
+		items := users()
+		Expect(items).To(HaveLen(2))
+		Expect(items[0].Name).To(Equal("jack"))
+		Expect(items[0].Active).To(BeTrue())
+		Expect(*items[0].Score).To(Equal(4.5))
+		Expect(items[0].CreatedAt.Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC))).To(BeTrue())
+		Expect(items[0].Avatar).To(Equal([]byte{0xca, 0xfe}))
+		Expect(items[1]).To(Equal(User{ID: 2, Name: "peter"}))
+	})
+
+	It("imports the rows from JSON", func() {
+		importer.Format = sqldata.FormatJSON
+
+		input := `[{"name":"jack","id":1,"active":true,"score":4.5,"avatar":"yv4="},` +
+			`{"id":2,"name":"peter","active":false,"score":null,"avatar":null}]`
+
+		count, err := importer.Import(strings.NewReader(input), "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeEquivalentTo(2))
+
+		items := users()
+		Expect(items).To(HaveLen(2))
+		Expect(items[0].Name).To(Equal("jack"))
+		Expect(items[0].Avatar).To(Equal([]byte{0xca, 0xfe}))
+		Expect(items[1]).To(Equal(User{ID: 2, Name: "peter"}))
+	})
+
+	It("imports the rows from SQL", func() {
+		importer.Format = sqldata.FormatSQL
+
+		input := `INSERT INTO "users" ("id", "name", "active") VALUES` + "\n" +
+			`(1, 'jack', 1),` + "\n" +
+			`(2, 'it''s peter', 0);` + "\n"
+
+		count, err := importer.Import(strings.NewReader(input), "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeEquivalentTo(2))
+		Expect(users()[1].Name).To(Equal("it's peter"))
+	})
+
+	It("imports the rows in batches", func() {
+		importer.BatchSize = 2
+		progress := []int64{}
+
+		importer.Progress = func(count int64) {
+			progress = append(progress, count)
+		}
+
+		input := "id,name\n1,jack\n2,peter\n3,john\n"
+
+		count, err := importer.Import(strings.NewReader(input), "users")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeEquivalentTo(3))
+		Expect(progress).To(Equal([]int64{2, 3}))
+	})
+
+	Context("when the columns are interval and numeric", func() {
+		BeforeEach(func() {
+			_, err := importer.DB.Exec("CREATE TABLE ledger (duration TEXT, amount TEXT)")
+			Expect(err).To(BeNil())
+
+			// the types of PostgreSQL
+			provider := &fake.SchemaProvider{}
+			provider.SchemaReturns(&sqlmodel.Schema{
+				Tables: []sqlmodel.Table{
+					{
+						Name: "ledger",
+						Columns: []sqlmodel.Column{
+							{Name: "duration", Type: sqlmodel.ColumnType{Name: "interval"}},
+							{Name: "amount", Type: sqlmodel.ColumnType{Name: "numeric", Precision: 30, PrecisionScale: 10}},
+						},
+					},
+				},
+			}, nil)
+
+			importer.Provider = provider
+		})
+
+		It("imports the values as strings", func() {
+			importer.Format = sqldata.FormatJSON
+
+			input := `[{"duration":"1 day","amount":12345678901234567890.1234567890}]`
+
+			_, err := importer.Import(strings.NewReader(input), "ledger")
+			Expect(err).NotTo(HaveOccurred())
+
+			row := map[string]interface{}{}
+			Expect(importer.DB.QueryRowx("SELECT * FROM ledger").MapScan(row)).To(Succeed())
+			Expect(row).To(Equal(map[string]interface{}{
+				"duration": "1 day",
+				"amount":   "12345678901234567890.1234567890",
+			}))
+		})
+	})
+
+	Context("when a row fails", func() {
+		It("rollbacks the transaction", func() {
+			importer.BatchSize = 1
+			input := "id,name,score\n1,jack,1\n2,peter,high\n"
+
+			_, err := importer.Import(strings.NewReader(input), "users")
+			Expect(err).To(MatchError(`row 2: strconv.ParseFloat: parsing "high": invalid syntax`))
+			Expect(users()).To(BeEmpty())
+		})
+	})
+
+	Context("when a column does not exist", func() {
+		It("returns an error", func() {
+			input := "id,email\n1,jack@example.com\n"
+
+			_, err := importer.Import(strings.NewReader(input), "users")
+			Expect(err).To(MatchError("column 'email' does not exist in table 'users'"))
+		})
+	})
+
+	Context("when a JSON object has an unexpected column", func() {
+		It("returns an error", func() {
+			importer.Format = sqldata.FormatJSON
+			input := `[{"id":1},{"id":2,"name":"peter"}]`
+
+			_, err := importer.Import(strings.NewReader(input), "users")
+			Expect(err).To(MatchError("row 2: unexpected column 'name'"))
+			Expect(users()).To(BeEmpty())
+		})
+	})
+})
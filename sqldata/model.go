@@ -0,0 +1,165 @@
+// Package sqldata provides primitives and functions to export and import the
+// data of database tables.
+package sqldata
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/phogolabs/prana/sqlmodel"
+)
+
+const (
+	// FormatCSV is comma-separated values with a header of column names. The
+	// NULL values are written as \N.
+	FormatCSV = "csv"
+	// FormatJSON is an array of JSON objects
+	FormatJSON = "json"
+	// FormatSQL is a script of INSERT statements
+	FormatSQL = "sql"
+)
+
+const (
+	// DefaultBatchSize is the default number of rows in a batch
+	DefaultBatchSize = 1000
+	// csvNull represents NULL value in CSV
+	csvNull = `\N`
+)
+
+const (
+	kindString = "string"
+	kindInt    = "int"
+	kindFloat  = "float"
+	kindBool   = "bool"
+	kindTime   = "time"
+	kindBinary = "binary"
+)
+
+// Progress reports the number of the processed rows
+type Progress func(count int64)
+
+// table returns the definition of a given table
+func table(provider sqlmodel.SchemaProvider, schema, name string) (*sqlmodel.Table, error) {
+	spec, err := provider.Schema(schema, name)
+	if err != nil {
+		return nil, err
+	}
+
+	for index := range spec.Tables {
+		table := &spec.Tables[index]
+
+		if table.Name == name && len(table.Columns) > 0 {
+			return table, nil
+		}
+	}
+
+	return nil, fmt.Errorf("table '%s' not found", name)
+}
+
+// kinds maps the names of the column types to the kinds of their values. The
+// exact numeric types (numeric, decimal and money) are kept as strings as
+// they cannot be represented by float64 without loss of precision.
+var kinds = map[string]string{
+	"bool":                        kindBool,
+	"boolean":                     kindBool,
+	"tinyint":                     kindInt,
+	"smallint":                    kindInt,
+	"mediumint":                   kindInt,
+	"int":                         kindInt,
+	"integer":                     kindInt,
+	"bigint":                      kindInt,
+	"big int":                     kindInt,
+	"int2":                        kindInt,
+	"int4":                        kindInt,
+	"int8":                        kindInt,
+	"smallserial":                 kindInt,
+	"serial":                      kindInt,
+	"bigserial":                   kindInt,
+	"real":                        kindFloat,
+	"float":                       kindFloat,
+	"float4":                      kindFloat,
+	"float8":                      kindFloat,
+	"double":                      kindFloat,
+	"double precision":            kindFloat,
+	"date":                        kindTime,
+	"datetime":                    kindTime,
+	"timestamp":                   kindTime,
+	"timestamptz":                 kindTime,
+	"timestamp with time zone":    kindTime,
+	"timestamp without time zone": kindTime,
+	"blob":                        kindBinary,
+	"tinyblob":                    kindBinary,
+	"mediumblob":                  kindBinary,
+	"longblob":                    kindBinary,
+	"binary":                      kindBinary,
+	"varbinary":                   kindBinary,
+	"bytea":                       kindBinary,
+}
+
+// kind returns the kind of the column values
+func kind(column *sqlmodel.Column) string {
+	if value, ok := kinds[typeName(column)]; ok {
+		return value
+	}
+
+	return kindString
+}
+
+// typeName returns the lower case name of the column type without its
+// length, precision and modifiers (e.g. int(11) unsigned is int)
+func typeName(column *sqlmodel.Column) string {
+	name := column.Type.Name
+
+	if strings.EqualFold(name, "USER-DEFINED") {
+		name = column.Type.Underlying
+	}
+
+	name = strings.ToLower(name)
+
+	if start := strings.Index(name, "("); start >= 0 {
+		if end := strings.Index(name[start:], ")"); end >= 0 {
+			name = name[:start] + " " + name[start+end+1:]
+		}
+	}
+
+	fields := []string{}
+
+	for _, field := range strings.Fields(name) {
+		if field != "unsigned" && field != "zerofill" {
+			fields = append(fields, field)
+		}
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// quote quotes an identifier for given dialect
+func quote(dialect, name string) string {
+	mark := `"`
+
+	if dialect == "mysql" {
+		mark = "`"
+	}
+
+	return mark + strings.Replace(name, mark, mark+mark, -1) + mark
+}
+
+// qualify returns the quoted name of a table in a given schema
+func qualify(dialect, schema, name string) string {
+	if schema == "" {
+		return quote(dialect, name)
+	}
+
+	return quote(dialect, schema) + "." + quote(dialect, name)
+}
+
+// columns returns the quoted names of the columns
+func columns(dialect string, names []string) string {
+	items := make([]string, len(names))
+
+	for index, name := range names {
+		items[index] = quote(dialect, name)
+	}
+
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,161 @@
+package sqldata
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// normalize converts the scanned value to the Go type of the column kind
+func normalize(kind string, value interface{}) interface{} {
+	switch value := value.(type) {
+	case []byte:
+		if kind == kindBinary {
+			return value
+		}
+
+		return normalize(kind, string(value))
+	case string:
+		// some drivers return the numeric and the boolean values as text
+		if parsed, err := parse(kind, value); err == nil && kind != kindBinary {
+			return parsed
+		}
+
+		return value
+	case int64:
+		if kind == kindBool {
+			return value != 0
+		}
+
+		return value
+	default:
+		return value
+	}
+}
+
+// parse converts the text to the Go type of the column kind
+func parse(kind, text string) (interface{}, error) {
+	switch kind {
+	case kindInt:
+		return strconv.ParseInt(text, 10, 64)
+	case kindFloat:
+		return strconv.ParseFloat(text, 64)
+	case kindBool:
+		return strconv.ParseBool(text)
+	case kindTime:
+		for _, layout := range timeLayouts {
+			if value, err := time.Parse(layout, text); err == nil {
+				return value, nil
+			}
+		}
+
+		return nil, fmt.Errorf("cannot parse '%s' as time", text)
+	case kindBinary:
+		return base64.StdEncoding.DecodeString(text)
+	default:
+		return text, nil
+	}
+}
+
+// text returns the value as text for CSV
+func text(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return csvNull
+	case []byte:
+		return base64.StdEncoding.EncodeToString(value)
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// marshal returns the value as JSON
+func marshal(value interface{}) ([]byte, error) {
+	switch value := value.(type) {
+	case []byte:
+		return json.Marshal(base64.StdEncoding.EncodeToString(value))
+	case time.Time:
+		return json.Marshal(value.Format(time.RFC3339Nano))
+	default:
+		return json.Marshal(value)
+	}
+}
+
+// unmarshal converts the decoded JSON value to the Go type of the column kind
+func unmarshal(kind string, value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case json.Number:
+		return parse(kind, value.String())
+	case string:
+		return parse(kind, value)
+	case bool:
+		return value, nil
+	default:
+		// the nested objects are stored as JSON text
+		data, err := json.Marshal(value)
+		return string(data), err
+	}
+}
+
+// literal returns the value as SQL literal for given dialect
+func literal(dialect string, value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if dialect == "sqlite3" {
+			if value {
+				return "1"
+			}
+
+			return "0"
+		}
+
+		return strings.ToUpper(strconv.FormatBool(value))
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	case []byte:
+		if dialect == "postgres" {
+			return fmt.Sprintf("decode('%s', 'hex')", hex.EncodeToString(value))
+		}
+
+		return fmt.Sprintf("X'%s'", hex.EncodeToString(value))
+	case time.Time:
+		switch dialect {
+		case "mysql":
+			return literal(dialect, value.UTC().Format("2006-01-02 15:04:05.999999"))
+		default:
+			return literal(dialect, value.Format("2006-01-02 15:04:05.999999999-07:00"))
+		}
+	case string:
+		text := strings.Replace(value, "'", "''", -1)
+
+		if dialect == "mysql" {
+			text = strings.Replace(text, `\`, `\\`, -1)
+		}
+
+		return "'" + text + "'"
+	default:
+		return literal(dialect, fmt.Sprintf("%v", value))
+	}
+}
@@ -0,0 +1,326 @@
+package sqlexec
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// CheckError represents a problem of a routine found by the Checker.
+type CheckError struct {
+	// Routine is the checked routine
+	Routine *Routine
+	// Line is the line of the problem in the routine file
+	Line int
+	// Err is the problem
+	Err error
+}
+
+// Error returns the problem with its location
+func (e *CheckError) Error() string {
+	return fmt.Sprintf("%s:%d: routine '%s': %v", e.Routine.File, e.Line, e.Routine.Name, e.Err)
+}
+
+// Checker validates the routines against the database without their
+// execution. Each statement is prepared by the database, which reports the
+// syntax errors and the unknown tables and columns. The number of the
+// placeholders is compared with the number of the parameters expected by the
+// database, the declared parameters and the parameters of the test cases.
+// The templates are checked as they are rendered without parameters.
+//
+// The statements of a routine are checked in order. The statements that
+// follow a schema change (e.g. CREATE TABLE) of the same routine are not
+// checked unless the schema changes are applied.
+type Checker struct {
+	// FileSystem represents the routine directory file system.
+	FileSystem FileSystem
+	// DB is a client to underlying database.
+	DB *sqlx.DB
+	// ApplySchema executes the schema changes of a routine in a transaction
+	// that is rolled back, so the following statements can use the created
+	// objects. It's supported only by the databases that roll back the schema
+	// changes (SQLite and PostgreSQL).
+	ApplySchema bool
+}
+
+// Check checks the routines with given names. It checks all routines if the
+// names are not provided.
+func (c *Checker) Check(names ...string) ([]*CheckError, error) {
+	if c.ApplySchema && !c.transactional() {
+		return nil, fmt.Errorf("the schema changes cannot be rolled back by '%s' driver", c.DB.DriverName())
+	}
+
+	provider := &Provider{
+		dialect: c.DB.DriverName(),
+	}
+
+	if err := provider.ReadDir(c.FileSystem); err != nil {
+		return nil, err
+	}
+
+	routines := provider.Routines()
+
+	if len(names) > 0 {
+		routines = []*Routine{}
+
+		for _, name := range names {
+			routine, err := provider.Routine(name)
+			if err != nil {
+				return nil, err
+			}
+
+			routines = append(routines, routine)
+		}
+	}
+
+	conn, err := c.DB.Connx(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	errs := []*CheckError{}
+
+	for _, routine := range routines {
+		errs = append(errs, c.check(conn, routine)...)
+	}
+
+	return errs, nil
+}
+
+func (c *Checker) check(conn *sqlx.Conn, routine *Routine) []*CheckError {
+	var (
+		errs  = []*CheckError{}
+		query = routine.Query
+		named = ParamNames(query)
+		start = routine.EndLine - strings.Count(query, "\n")
+	)
+
+	report := func(line int, err error) {
+		errs = append(errs, &CheckError{Routine: routine, Line: line, Err: err})
+	}
+
+	if IsTemplate(query) {
+		rendered, _, err := Render(c.DB.DriverName(), query, nil)
+		if err != nil {
+			report(start, err)
+			return errs
+		}
+
+		query = rendered
+		named = nil
+	}
+
+	var (
+		input    = []rune(query)
+		segments = newSplitter(c.DB.DriverName(), query).split()
+		count    = 0
+		skip     = false
+		tx       *sqlx.Tx
+	)
+
+	defer func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for index, segment := range segments {
+		statement := segment.text
+
+		leading := len(statement) - len(strings.TrimLeft(statement, " \t\r\n"))
+		line := start + strings.Count(string(input[:segment.start+leading]), "\n")
+
+		if len(named) > 0 {
+			// the values are not needed to prepare the statement
+			params := map[string]interface{}{}
+
+			for _, name := range ParamNames(statement) {
+				params[name] = nil
+			}
+
+			bound, _, err := BindNamed(statement, params)
+			if err != nil {
+				report(line, err)
+				continue
+			}
+
+			statement = bound
+		}
+
+		statement = c.DB.Rebind(statement)
+		placeholders := Placeholders(statement)
+		count += placeholders
+
+		// the statement may depend on a schema change that is not applied
+		if skip {
+			continue
+		}
+
+		expected, err := c.prepare(conn, statement)
+
+		switch {
+		case err != nil:
+			report(line, err)
+			// the failed statement aborts the transaction in PostgreSQL
+			skip = tx != nil
+			continue
+		case expected >= 0 && expected != placeholders:
+			report(line, fmt.Errorf("statement has %d placeholders but the database expects %d parameters", placeholders, expected))
+		}
+
+		if index == len(segments)-1 || !schemaRgxp.MatchString(strip(statement)) {
+			continue
+		}
+
+		// the following statements cannot see the schema change
+		if !c.ApplySchema || placeholders > 0 {
+			skip = true
+			continue
+		}
+
+		if tx, err = c.apply(conn, tx, statement); err != nil {
+			report(line, err)
+			skip = true
+		}
+	}
+
+	for _, err := range c.params(routine, named, count) {
+		report(routine.StartLine, err)
+	}
+
+	return errs
+}
+
+// params compares the placeholders with the declared parameters and the
+// parameters of the test cases
+func (c *Checker) params(routine *Routine, named []string, count int) []error {
+	errs := []error{}
+
+	if len(named) == 0 {
+		if len(routine.Params) > 0 && len(routine.Params) != count {
+			errs = append(errs, fmt.Errorf("routine declares %d parameters but has %d placeholders", len(routine.Params), count))
+		}
+
+		for index, test := range routine.Tests {
+			if len(test.Params) != count {
+				errs = append(errs, fmt.Errorf("test case %d has %d parameters but the routine has %d placeholders", index+1, len(test.Params), count))
+			}
+		}
+
+		return errs
+	}
+
+	used := map[string]bool{}
+
+	for _, name := range named {
+		used[name] = true
+	}
+
+	if len(routine.Params) > 0 {
+		declared := map[string]bool{}
+
+		for _, param := range routine.Params {
+			declared[param.Name] = true
+
+			if !used[param.Name] {
+				errs = append(errs, fmt.Errorf("parameter '%s' is declared but not used", param.Name))
+			}
+		}
+
+		for _, name := range keys(used) {
+			if !declared[name] {
+				errs = append(errs, fmt.Errorf("parameter '%s' is used but not declared", name))
+			}
+		}
+	}
+
+	for index, test := range routine.Tests {
+		provided := map[string]bool{}
+
+		for _, arg := range test.Params {
+			provided[arg.Name] = true
+		}
+
+		for _, name := range keys(used) {
+			if !provided[name] {
+				errs = append(errs, fmt.Errorf("test case %d does not have parameter '%s'", index+1, name))
+			}
+		}
+	}
+
+	return errs
+}
+
+// transactional returns true if the database can roll back the schema changes
+func (c *Checker) transactional() bool {
+	switch c.DB.DriverName() {
+	case "sqlite3", "postgres":
+		return true
+	default:
+		return false
+	}
+}
+
+// apply executes the schema change in the transaction. The transaction is
+// started if it's not provided.
+func (c *Checker) apply(conn *sqlx.Conn, tx *sqlx.Tx, statement string) (*sqlx.Tx, error) {
+	if tx == nil {
+		var err error
+
+		if tx, err = conn.BeginTxx(context.Background(), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err := tx.Exec(statement)
+	return tx, err
+}
+
+// prepare prepares the statement with the driver and returns the number of
+// the parameters expected by the database or -1 if the driver does not know
+// it.
+func (c *Checker) prepare(conn *sqlx.Conn, statement string) (int, error) {
+	count := -1
+
+	err := conn.Raw(func(raw interface{}) error {
+		var (
+			stmt driver.Stmt
+			err  error
+		)
+
+		switch conn := raw.(type) {
+		case driver.ConnPrepareContext:
+			stmt, err = conn.PrepareContext(context.Background(), statement)
+		case driver.Conn:
+			stmt, err = conn.Prepare(statement)
+		default:
+			return fmt.Errorf("unsupported driver connection %T", raw)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		count = stmt.NumInput()
+		return stmt.Close()
+	})
+
+	return count, err
+}
+
+func keys(values map[string]bool) []string {
+	names := []string{}
+
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,229 @@
+package sqlexec_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checker", func() {
+	var (
+		checker *sqlexec.Checker
+		storage fstest.MapFS
+	)
+
+	messages := func(errs []*sqlexec.CheckError) []string {
+		items := []string{}
+
+		for _, err := range errs {
+			items = append(items, err.Error())
+		}
+
+		return items
+	}
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "prana_checker")
+		Expect(err).To(BeNil())
+
+		db, err := sqlx.Open("sqlite3", filepath.Join(dir, "prana.db"))
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+		Expect(err).To(BeNil())
+
+		routines := &bytes.Buffer{}
+		fmt.Fprintln(routines, "-- name: select-user")
+		fmt.Fprintln(routines, "-- param: id")
+		fmt.Fprintln(routines, "-- test: params=id:int=1 expect=golden/select-user.json")
+		fmt.Fprintln(routines, "SELECT * FROM users WHERE id = :id;")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: update-user")
+		fmt.Fprintln(routines, "UPDATE users SET name = ? WHERE id = ?;")
+		fmt.Fprintln(routines, "DELETE FROM users WHERE id = ?;")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: search-users")
+		fmt.Fprintln(routines, "SELECT * FROM users {{if .name}}WHERE name = {{bind .name}}{{end}}")
+
+		storage = fstest.MapFS{
+			"users.sql": &fstest.MapFile{Data: routines.Bytes()},
+		}
+
+		checker = &sqlexec.Checker{
+			FileSystem: storage,
+			DB:         db,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(checker.DB.Close()).To(Succeed())
+	})
+
+	It("checks the routines successfully", func() {
+		errs, err := checker.Check()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(BeEmpty())
+	})
+
+	Context("when a routine has errors", func() {
+		BeforeEach(func() {
+			routines := &bytes.Buffer{}
+			fmt.Fprintln(routines, "-- name: select-roles")
+			fmt.Fprintln(routines, "SELECT * FROM roles;")
+			fmt.Fprintln(routines)
+			fmt.Fprintln(routines, "-- name: update-role")
+			fmt.Fprintln(routines, "-- doc: Updates the role")
+			fmt.Fprintln(routines)
+			fmt.Fprintln(routines, "UPDATE users SET name = ? WHERE id = ?;")
+			fmt.Fprintln(routines)
+			fmt.Fprintln(routines, "  UPDATE users SET role = ? WHERE id = ?;")
+			fmt.Fprintln(routines)
+			fmt.Fprintln(routines, "-- name: select-users")
+			fmt.Fprintln(routines, "SELECT * FROM users WHERE;")
+
+			storage["billing/roles.sql"] = &fstest.MapFile{Data: routines.Bytes()}
+		})
+
+		It("reports the errors with their locations", func() {
+			errs, err := checker.Check("select-roles", "update-role", "billing.select-users")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages(errs)).To(Equal([]string{
+				"billing/roles.sql:2: routine 'billing.select-roles': no such table: roles",
+				"billing/roles.sql:9: routine 'billing.update-role': no such column: role",
+				"billing/roles.sql:12: routine 'billing.select-users': near \";\": syntax error",
+			}))
+		})
+	})
+
+	Context("when the parameters do not match the placeholders", func() {
+		BeforeEach(func() {
+			routines := &bytes.Buffer{}
+			fmt.Fprintln(routines, "-- name: select-user")
+			fmt.Fprintln(routines, "-- param: id")
+			fmt.Fprintln(routines, "-- param: role")
+			fmt.Fprintln(routines, "-- test: params=role=admin expect=golden/select-user.json")
+			fmt.Fprintln(routines, "SELECT * FROM users WHERE id = :id AND name = :name;")
+			fmt.Fprintln(routines)
+			fmt.Fprintln(routines, "-- name: delete-user")
+			fmt.Fprintln(routines, "-- param: id")
+			fmt.Fprintln(routines, "-- param: name")
+			fmt.Fprintln(routines, "-- test: params=:int=1 expect=golden/delete-user.json")
+			fmt.Fprintln(routines, "DELETE FROM users WHERE id = ?;")
+
+			storage["users.sql"] = &fstest.MapFile{Data: routines.Bytes()}
+		})
+
+		It("reports the mismatches", func() {
+			errs, err := checker.Check()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages(errs)).To(Equal([]string{
+				"users.sql:7: routine 'delete-user': routine declares 2 parameters but has 1 placeholders",
+				"users.sql:1: routine 'select-user': parameter 'role' is declared but not used",
+				"users.sql:1: routine 'select-user': parameter 'name' is used but not declared",
+				"users.sql:1: routine 'select-user': test case 1 does not have parameter 'id'",
+				"users.sql:1: routine 'select-user': test case 1 does not have parameter 'name'",
+			}))
+		})
+	})
+
+	Context("when the database expects different number of parameters", func() {
+		BeforeEach(func() {
+			storage["users.sql"] = &fstest.MapFile{
+				Data: []byte("-- name: select-user\nSELECT * FROM users WHERE id = ?1 OR name = ?1;\n"),
+			}
+		})
+
+		It("reports the mismatch", func() {
+			errs, err := checker.Check()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages(errs)).To(Equal([]string{
+				"users.sql:2: routine 'select-user': statement has 2 placeholders but the database expects 1 parameters",
+			}))
+		})
+	})
+
+	Context("when a statement depends on a schema change of the routine", func() {
+		BeforeEach(func() {
+			routines := &bytes.Buffer{}
+			fmt.Fprintln(routines, "-- name: archive-users")
+			fmt.Fprintln(routines, "CREATE TABLE archive (id INTEGER, name TEXT);")
+			fmt.Fprintln(routines, "INSERT INTO archive (id, name) SELECT id, name FROM users WHERE id = ?;")
+			fmt.Fprintln(routines, "SELECT unknown FROM archive;")
+
+			storage["users.sql"] = &fstest.MapFile{Data: routines.Bytes()}
+		})
+
+		It("does not check the following statements", func() {
+			errs, err := checker.Check()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(errs).To(BeEmpty())
+
+			count := 0
+			Expect(checker.DB.Get(&count, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'archive'")).To(Succeed())
+			Expect(count).To(BeZero())
+		})
+
+		Context("when the schema changes are applied", func() {
+			BeforeEach(func() {
+				checker.ApplySchema = true
+			})
+
+			It("checks the statement against the changed schema", func() {
+				errs, err := checker.Check()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(messages(errs)).To(Equal([]string{
+					"users.sql:4: routine 'archive-users': no such column: unknown",
+				}))
+
+				count := 0
+				Expect(checker.DB.Get(&count, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'archive'")).To(Succeed())
+				Expect(count).To(BeZero())
+			})
+
+			Context("when the database cannot roll back the schema changes", func() {
+				It("returns an error", func() {
+					checker.DB = sqlx.NewDb(checker.DB.DB, "mysql")
+
+					_, err := checker.Check()
+					Expect(err).To(MatchError("the schema changes cannot be rolled back by 'mysql' driver"))
+				})
+			})
+		})
+	})
+
+	Context("when the routine changes the delimiter", func() {
+		BeforeEach(func() {
+			routines := &bytes.Buffer{}
+			fmt.Fprintln(routines, "-- name: proc")
+			fmt.Fprintln(routines, "DELIMITER //")
+			fmt.Fprintln(routines, "SELECT 1//")
+			fmt.Fprintln(routines, "SELECT * FROM roles//")
+			fmt.Fprintln(routines, "DELIMITER ;")
+
+			storage["users.sql"] = &fstest.MapFile{Data: routines.Bytes()}
+		})
+
+		It("reports the errors with their locations", func() {
+			errs, err := checker.Check()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages(errs)).To(Equal([]string{
+				"users.sql:4: routine 'proc': no such table: roles",
+			}))
+		})
+	})
+
+	Context("when the routine does not exist", func() {
+		It("returns an error", func() {
+			_, err := checker.Check("unknown")
+			Expect(err).To(MatchError("query 'unknown' not found"))
+		})
+	})
+})
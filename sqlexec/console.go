@@ -0,0 +1,325 @@
+package sqlexec
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/chzyer/readline"
+)
+
+const (
+	consolePrompt       = "prana> "
+	consoleContinuation = "   ..> "
+)
+
+// Console is an interactive shell that executes ad-hoc SQL statements and
+// the routines of the Runner. The line editing and the history are provided
+// by readline. The SQL statements can span multiple lines and are executed
+// once the input is terminated according to the rules of the Splitter (e.g.
+// a semicolon inside a string literal or a comment does not end the input).
+// The following commands are supported:
+//
+//	\r name [param ...]  runs a routine with [name][:type]=value parameters
+//	\l                   lists the routines
+//	\f format            changes the output format
+//	\h                   prints the history
+//	\?                   prints the help
+//	\q                   quits the console
+type Console struct {
+	// Runner runs the routines and the SQL statements
+	Runner *Runner
+	// Printer prints the rows
+	Printer *Printer
+	// Reader is the input of the console. The terminal is used if it is not
+	// provided.
+	Reader io.Reader
+	// Writer is the output of the console
+	Writer io.Writer
+	// HistoryFile is the path to the file that persists the history
+	HistoryFile string
+	// History contains the executed entries
+	History []string
+}
+
+// Run runs the console until the input ends or the quit command is entered.
+func (c *Console) Run() error {
+	if c.Printer == nil {
+		c.Printer = &Printer{}
+	}
+
+	if c.Writer == nil {
+		c.Writer = os.Stdout
+	}
+
+	config := &readline.Config{
+		Prompt:                 consolePrompt,
+		Stdout:                 c.Writer,
+		Stderr:                 c.Writer,
+		DisableAutoSaveHistory: true,
+	}
+
+	if c.Reader != nil {
+		config.Stdin = ioutil.NopCloser(c.Reader)
+		config.FuncIsTerminal = func() bool { return false }
+	}
+
+	reader, err := readline.NewEx(config)
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	if err := c.load(reader); err != nil {
+		return err
+	}
+
+	buffer := []string{}
+
+	for {
+		if len(buffer) == 0 {
+			reader.SetPrompt(consolePrompt)
+		} else {
+			reader.SetPrompt(consoleContinuation)
+		}
+
+		line, err := reader.Readline()
+
+		switch {
+		case err == readline.ErrInterrupt:
+			// the interrupt discards the pending input
+			buffer = []string{}
+			continue
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+
+		text := strings.TrimSpace(line)
+
+		switch {
+		case len(buffer) == 0 && text == "":
+		case len(buffer) == 0 && strings.HasPrefix(text, "\\"):
+			if text == "\\q" {
+				return nil
+			}
+
+			c.record(reader, text)
+			c.report(c.command(text))
+		default:
+			buffer = append(buffer, line)
+
+			input := strings.Join(buffer, "\n")
+
+			statements, ok := c.split(input)
+			if !ok {
+				continue
+			}
+
+			buffer = []string{}
+
+			c.record(reader, input)
+
+			for _, statement := range statements {
+				if err := c.query(statement); err != nil {
+					c.report(err)
+					break
+				}
+			}
+		}
+	}
+}
+
+// split splits the input into statements. It returns false if the input is
+// not terminated yet.
+func (c *Console) split(input string) ([]string, bool) {
+	lexer := newSplitter(c.Runner.DB.DriverName(), input)
+
+	statements := []string{}
+
+	for _, segment := range lexer.split() {
+		statements = append(statements, segment.text)
+	}
+
+	if !lexer.terminated() {
+		return nil, false
+	}
+
+	return statements, true
+}
+
+func (c *Console) report(err error) {
+	if err != nil {
+		fmt.Fprintf(c.Writer, "ERROR: %v\n", err)
+	}
+}
+
+func (c *Console) command(text string) error {
+	fields := strings.Fields(text)
+
+	switch fields[0] {
+	case "\\r":
+		if len(fields) < 2 {
+			return fmt.Errorf("the routine name is missing")
+		}
+
+		return c.routine(fields[1], fields[2:])
+	case "\\l":
+		routines, err := c.Runner.Routines()
+		if err != nil {
+			return err
+		}
+
+		for _, routine := range routines {
+			fmt.Fprintln(c.Writer, routine.Name)
+		}
+
+		return nil
+	case "\\f":
+		if len(fields) != 2 {
+			return fmt.Errorf("the format is missing")
+		}
+
+		c.Printer.Format = fields[1]
+		return nil
+	case "\\h":
+		for index, entry := range c.History {
+			fmt.Fprintf(c.Writer, "%5d  %s\n", index+1, entry)
+		}
+
+		return nil
+	case "\\?":
+		fmt.Fprintln(c.Writer, `\r name [param ...]  run a routine with [name][:type]=value parameters`)
+		fmt.Fprintln(c.Writer, `\l                   list the routines`)
+		fmt.Fprintln(c.Writer, `\f format            change the output format`)
+		fmt.Fprintln(c.Writer, `\h                   print the history`)
+		fmt.Fprintln(c.Writer, `\q                   quit`)
+		return nil
+	default:
+		return fmt.Errorf("unknown command '%s'. Type \\? for help", fields[0])
+	}
+}
+
+func (c *Console) routine(name string, texts []string) error {
+	params := []*Arg{}
+
+	for _, text := range texts {
+		arg, err := ParseArg(text)
+		if err != nil {
+			return err
+		}
+
+		params = append(params, arg)
+	}
+
+	args, err := Args(params)
+	if err != nil {
+		return err
+	}
+
+	routine, err := c.Runner.Routine(name)
+	if err != nil {
+		return err
+	}
+
+	if !ReturnsRows(routine) {
+		results, err := c.Runner.Exec(routine.Name, args...)
+		if err != nil {
+			return err
+		}
+
+		return c.Printer.PrintResults(c.Writer, results)
+	}
+
+	rows, err := c.Runner.Run(routine.Name, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	return c.Printer.Print(c.Writer, rows)
+}
+
+func (c *Console) query(statement string) error {
+	if !IsQuery(statement) {
+		result, err := c.Runner.DB.Exec(statement)
+		if err != nil {
+			return err
+		}
+
+		item := &Result{Statement: statement}
+		item.RowsAffected, _ = result.RowsAffected()
+		item.LastInsertID, _ = result.LastInsertId()
+
+		return c.Printer.PrintResults(c.Writer, []*Result{item})
+	}
+
+	rows, err := c.Runner.DB.Queryx(statement)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	return c.Printer.Print(c.Writer, rows)
+}
+
+// load loads the history file into the history of readline. The entries are
+// separated by NUL character in the history file as they can span multiple
+// lines.
+func (c *Console) load(reader *readline.Instance) error {
+	for _, entry := range c.History {
+		reader.SaveHistory(entry)
+	}
+
+	if c.HistoryFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.HistoryFile)
+
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	for _, entry := range strings.Split(string(data), "\x00\n") {
+		if entry != "" {
+			c.History = append(c.History, entry)
+			reader.SaveHistory(entry)
+		}
+	}
+
+	return nil
+}
+
+// record appends the entry to the history and to the history file
+func (c *Console) record(reader *readline.Instance, entry string) {
+	c.History = append(c.History, entry)
+
+	if err := reader.SaveHistory(entry); err != nil {
+		c.report(err)
+	}
+
+	if c.HistoryFile == "" {
+		return
+	}
+
+	file, err := os.OpenFile(c.HistoryFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		c.report(err)
+		return
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, "%s\x00\n", entry); err != nil {
+		c.report(err)
+	}
+}
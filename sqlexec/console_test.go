@@ -0,0 +1,198 @@
+package sqlexec_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Console", func() {
+	var (
+		console *sqlexec.Console
+		input   *bytes.Buffer
+		output  *bytes.Buffer
+		dir     string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "prana_console")
+		Expect(err).To(BeNil())
+
+		gateway, err := sqlx.Open("sqlite3", filepath.Join(dir, "prana.db"))
+		Expect(err).To(BeNil())
+
+		_, err = gateway.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+		Expect(err).To(BeNil())
+
+		_, err = gateway.Exec("INSERT INTO users (id, name) VALUES (1, 'Jack'), (2, 'Peter')")
+		Expect(err).To(BeNil())
+
+		routines := &bytes.Buffer{}
+		fmt.Fprintln(routines, "-- name: select-user-by-pk")
+		fmt.Fprintln(routines, "SELECT name FROM users WHERE id = :id")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: delete-user-by-pk")
+		fmt.Fprintln(routines, "DELETE FROM users WHERE id = ?")
+
+		input = &bytes.Buffer{}
+		output = &bytes.Buffer{}
+
+		console = &sqlexec.Console{
+			Runner: &sqlexec.Runner{
+				FileSystem: fstest.MapFS{
+					"users.sql": &fstest.MapFile{Data: routines.Bytes()},
+				},
+				DB: gateway,
+			},
+			Printer: &sqlexec.Printer{Format: sqlexec.FormatJSON},
+			Reader:  input,
+			Writer:  output,
+		}
+	})
+
+	AfterEach(func() {
+		console.Runner.DB.Close()
+	})
+
+	It("runs a multi-line statement terminated by semicolon", func() {
+		fmt.Fprintln(input, "SELECT name")
+		fmt.Fprintln(input, "FROM users")
+		fmt.Fprintln(input, "WHERE id = 2;")
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring(`{"name":"Peter"}`))
+		Expect(output.String()).NotTo(ContainSubstring("Jack"))
+		Expect(console.History).To(Equal([]string{"SELECT name\nFROM users\nWHERE id = 2;"}))
+	})
+
+	It("does not terminate the statement by a semicolon in a string literal", func() {
+		fmt.Fprintln(input, "INSERT INTO users (id, name) VALUES (3, 'John;")
+		fmt.Fprintln(input, "Doe');")
+		fmt.Fprintln(input, "SELECT name FROM users WHERE id = 3;")
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring(`{"name":"John;\nDoe"}`))
+		Expect(console.History).To(HaveLen(2))
+	})
+
+	It("does not terminate the statement by a semicolon in a comment", func() {
+		fmt.Fprintln(input, "SELECT name /* the name;")
+		fmt.Fprintln(input, "of the user; */ FROM users -- only Jack;")
+		fmt.Fprintln(input, "WHERE id = 1;")
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring(`{"name":"Jack"}`))
+		Expect(output.String()).NotTo(ContainSubstring("ERROR"))
+		Expect(console.History).To(HaveLen(1))
+	})
+
+	It("runs each statement of the input", func() {
+		fmt.Fprintln(input, "DELETE FROM users WHERE id = 1; SELECT COUNT(*) AS count FROM users;")
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring(`"rows_affected":1`))
+		Expect(output.String()).To(ContainSubstring(`{"count":1}`))
+	})
+
+	It("executes a statement that does not return rows", func() {
+		fmt.Fprintln(input, "DELETE FROM users;")
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring(`[{"statement":1,"rows_affected":2,"last_insert_id":2}]`))
+	})
+
+	It("prints the results of a routine in the format", func() {
+		fmt.Fprintln(input, `\f csv`)
+		fmt.Fprintln(input, `\r delete-user-by-pk :int=1`)
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring("statement,rows_affected,last_insert_id\n1,1,"))
+	})
+
+	It("runs a routine with named parameters", func() {
+		fmt.Fprintln(input, `\r select-user-by-pk id:int=1`)
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring(`{"name":"Jack"}`))
+	})
+
+	It("executes a routine with positional parameters", func() {
+		fmt.Fprintln(input, `\r delete-user-by-pk :int=1`)
+		fmt.Fprintln(input, "SELECT COUNT(*) AS count FROM users;")
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring(`{"count":1}`))
+	})
+
+	It("lists the routines", func() {
+		fmt.Fprintln(input, `\l`)
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring("delete-user-by-pk\nselect-user-by-pk\n"))
+	})
+
+	It("changes the format", func() {
+		fmt.Fprintln(input, `\f csv`)
+		fmt.Fprintln(input, "SELECT name FROM users WHERE id = 1;")
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).To(ContainSubstring("name\nJack\n"))
+	})
+
+	It("stops at the quit command", func() {
+		fmt.Fprintln(input, `\q`)
+		fmt.Fprintln(input, "DELETE FROM users;")
+
+		Expect(console.Run()).To(Succeed())
+		Expect(output.String()).NotTo(ContainSubstring("ROWS AFFECTED"))
+	})
+
+	Context("when the statement fails", func() {
+		It("reports the error and continues", func() {
+			fmt.Fprintln(input, "SELECT * FROM unknown;")
+			fmt.Fprintln(input, `\r unknown`)
+			fmt.Fprintln(input, `\x`)
+			fmt.Fprintln(input, "SELECT name FROM users WHERE id = 1;")
+
+			Expect(console.Run()).To(Succeed())
+			Expect(output.String()).To(ContainSubstring("ERROR: no such table: unknown"))
+			Expect(output.String()).To(ContainSubstring("ERROR: query 'unknown' not found"))
+			Expect(output.String()).To(ContainSubstring(`ERROR: unknown command '\x'`))
+			Expect(output.String()).To(ContainSubstring(`{"name":"Jack"}`))
+		})
+	})
+
+	Context("when the history file is provided", func() {
+		BeforeEach(func() {
+			console.HistoryFile = filepath.Join(dir, "history")
+		})
+
+		It("persists the history", func() {
+			fmt.Fprintln(input, "SELECT name")
+			fmt.Fprintln(input, "FROM users;")
+			fmt.Fprintln(input, `\l`)
+
+			Expect(console.Run()).To(Succeed())
+
+			console.History = nil
+			console.Reader = strings.NewReader("\\h\n")
+			output.Reset()
+
+			Expect(console.Run()).To(Succeed())
+			Expect(console.History).To(Equal([]string{"SELECT name\nFROM users;", `\l`, `\h`}))
+			Expect(output.String()).To(ContainSubstring("    1  SELECT name\nFROM users;\n"))
+			Expect(output.String()).To(ContainSubstring(`    2  \l`))
+		})
+	})
+})
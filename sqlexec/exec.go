@@ -0,0 +1,129 @@
+package sqlexec
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Select runs a given routine and scans the rows into the dest slice. The
+// arguments are positional or a single map or struct of named parameters.
+func (p *Provider) Select(ctx context.Context, db sqlx.QueryerContext, dest interface{}, name string, args ...Param) error {
+	if err := p.transaction(db, name); err != nil {
+		return err
+	}
+
+	query, args, err := p.statement(name, args)
+	if err != nil {
+		return err
+	}
+
+	return sqlx.SelectContext(ctx, db, dest, query, args...)
+}
+
+// Get runs a given routine and scans the first row into the dest. It returns
+// sql.ErrNoRows if the routine does not return rows. The arguments are
+// positional or a single map or struct of named parameters.
+func (p *Provider) Get(ctx context.Context, db sqlx.QueryerContext, dest interface{}, name string, args ...Param) error {
+	if err := p.transaction(db, name); err != nil {
+		return err
+	}
+
+	query, args, err := p.statement(name, args)
+	if err != nil {
+		return err
+	}
+
+	return sqlx.GetContext(ctx, db, dest, query, args...)
+}
+
+// Exec executes a given routine that does not return rows. The arguments are
+// positional or a single map or struct of named parameters.
+func (p *Provider) Exec(ctx context.Context, db sqlx.ExecerContext, name string, args ...Param) (sql.Result, error) {
+	if err := p.transaction(db, name); err != nil {
+		return nil, err
+	}
+
+	query, args, err := p.statement(name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.ExecContext(ctx, query, args...)
+}
+
+// NamedExec executes a given routine that does not return rows with a map or
+// a struct of named parameters.
+func (p *Provider) NamedExec(ctx context.Context, db sqlx.ExecerContext, name string, arg Param) (sql.Result, error) {
+	if !IsNamedParam(arg) {
+		return nil, fmt.Errorf("query '%s' expects a map or a struct of named parameters but got %T", name, arg)
+	}
+
+	if err := p.transaction(db, name); err != nil {
+		return nil, err
+	}
+
+	query, args, err := p.Render(name, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.ExecContext(ctx, query, args...)
+}
+
+// statement returns the query of a given routine and its arguments. The
+// templates and the routines with named parameters are rendered with a single
+// map or struct argument.
+func (p *Provider) statement(name string, args []Param) (string, []Param, error) {
+	routine, err := p.Routine(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	templated := IsTemplate(routine.Query)
+
+	if !templated && !(IsNamedParam(args...) && len(ParamNames(routine.Query)) > 0) {
+		query, err := p.Query(routine.Name)
+		return query, args, err
+	}
+
+	var param Param
+
+	switch {
+	case len(args) == 0:
+	case IsNamedParam(args...):
+		param = args[0]
+	default:
+		return "", nil, fmt.Errorf("query '%s' is a template that expects named parameters", name)
+	}
+
+	return p.Render(routine.Name, param)
+}
+
+// transaction returns an error if the executor does not match the tx
+// annotation of a given routine. The executor is a transaction if it's
+// *sqlx.Tx or *sql.Tx.
+func (p *Provider) transaction(db interface{}, name string) error {
+	routine, err := p.Routine(name)
+	if err != nil {
+		return err
+	}
+
+	var tx bool
+
+	switch db.(type) {
+	case *sqlx.Tx, *sql.Tx:
+		tx = true
+	}
+
+	switch {
+	case routine.Tx == TxRequired && !tx:
+		return fmt.Errorf("query '%s' must be executed in a transaction", routine.Name)
+	case routine.Tx == TxNone && tx:
+		return fmt.Errorf("query '%s' cannot be executed in a transaction", routine.Name)
+	default:
+		return nil
+	}
+}
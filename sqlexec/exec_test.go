@@ -0,0 +1,218 @@
+package sqlexec_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Provider Exec", func() {
+	type User struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+
+	var (
+		ctx      context.Context
+		db       *sqlx.DB
+		provider *sqlexec.Provider
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		ctx = context.Background()
+
+		db, err = sqlx.Open("sqlite3", ":memory:")
+		Expect(err).To(BeNil())
+
+		// the in-memory database lives as long as its connection
+		db.SetMaxOpenConns(1)
+
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("INSERT INTO users (id, name) VALUES (1, 'jack'), (2, 'peter')")
+		Expect(err).To(BeNil())
+
+		routines := &bytes.Buffer{}
+		fmt.Fprintln(routines, "-- name: select-users")
+		fmt.Fprintln(routines, "SELECT * FROM users ORDER BY id")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: select-user")
+		fmt.Fprintln(routines, "SELECT * FROM users WHERE id = ?")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: select-user-by-name")
+		fmt.Fprintln(routines, "SELECT * FROM users WHERE name = :name")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: search-users")
+		fmt.Fprintln(routines, "SELECT * FROM users WHERE id IN {{in .ids}} ORDER BY id")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: insert-user")
+		fmt.Fprintln(routines, "INSERT INTO users (id, name) VALUES (:id, :name)")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: delete-user")
+		fmt.Fprintln(routines, "DELETE FROM users WHERE id = ?")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: delete-users")
+		fmt.Fprintln(routines, "-- tx: required")
+		fmt.Fprintln(routines, "DELETE FROM users")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: vacuum")
+		fmt.Fprintln(routines, "-- tx: none")
+		fmt.Fprintln(routines, "VACUUM")
+
+		provider = &sqlexec.Provider{}
+		provider.SetDialect("sqlite3")
+
+		_, err = provider.ReadFrom(routines)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	Describe("Select", func() {
+		It("scans the rows", func() {
+			users := []User{}
+			Expect(provider.Select(ctx, db, &users, "select-users")).To(Succeed())
+			Expect(users).To(Equal([]User{{ID: 1, Name: "jack"}, {ID: 2, Name: "peter"}}))
+		})
+
+		It("scans the rows of a template", func() {
+			users := []User{}
+			param := map[string]interface{}{"ids": []int{2, 3}}
+
+			Expect(provider.Select(ctx, db, &users, "search-users", param)).To(Succeed())
+			Expect(users).To(Equal([]User{{ID: 2, Name: "peter"}}))
+		})
+
+		Context("when the template has positional parameters", func() {
+			It("returns an error", func() {
+				users := []User{}
+				err := provider.Select(ctx, db, &users, "search-users", 1)
+				Expect(err).To(MatchError("query 'search-users' is a template that expects named parameters"))
+			})
+		})
+
+		Context("when the routine does not exist", func() {
+			It("returns an error", func() {
+				users := []User{}
+				err := provider.Select(ctx, db, &users, "unknown")
+				Expect(err).To(MatchError("query 'unknown' not found"))
+			})
+		})
+	})
+
+	Describe("Get", func() {
+		It("scans the row with positional parameters", func() {
+			user := User{}
+			Expect(provider.Get(ctx, db, &user, "select-user", 2)).To(Succeed())
+			Expect(user).To(Equal(User{ID: 2, Name: "peter"}))
+		})
+
+		It("scans the row with named parameters", func() {
+			user := User{}
+			Expect(provider.Get(ctx, db, &user, "select-user-by-name", User{Name: "jack"})).To(Succeed())
+			Expect(user).To(Equal(User{ID: 1, Name: "jack"}))
+		})
+
+		Context("when the row does not exist", func() {
+			It("returns an error", func() {
+				user := User{}
+				Expect(provider.Get(ctx, db, &user, "select-user", 3)).To(MatchError(sql.ErrNoRows))
+			})
+		})
+	})
+
+	Describe("Exec", func() {
+		It("executes the routine", func() {
+			result, err := provider.Exec(ctx, db, "delete-user", 1)
+			Expect(err).To(Succeed())
+			Expect(result.RowsAffected()).To(BeEquivalentTo(1))
+		})
+
+		It("executes the routine with a single time parameter", func() {
+			_, err := db.Exec("CREATE TABLE events (created_at DATETIME)")
+			Expect(err).To(Succeed())
+
+			_, err = provider.ReadFrom(bytes.NewBufferString("-- name: insert-event\nINSERT INTO events (created_at) VALUES (?)\n"))
+			Expect(err).To(Succeed())
+
+			result, err := provider.Exec(ctx, db, "insert-event", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+			Expect(err).To(Succeed())
+			Expect(result.RowsAffected()).To(BeEquivalentTo(1))
+		})
+	})
+
+	Context("when the routine requires a transaction", func() {
+		It("executes the routine in a transaction", func() {
+			tx, err := db.Beginx()
+			Expect(err).To(Succeed())
+
+			_, err = provider.Exec(ctx, tx, "delete-users")
+			Expect(err).To(Succeed())
+			Expect(tx.Rollback()).To(Succeed())
+		})
+
+		It("returns an error for an executor that is not a transaction", func() {
+			_, err := provider.Exec(ctx, db, "delete-users")
+			Expect(err).To(MatchError("query 'delete-users' must be executed in a transaction"))
+
+			users := []User{}
+			Expect(provider.Select(ctx, db, &users, "select-users")).To(Succeed())
+			Expect(users).To(HaveLen(2))
+		})
+	})
+
+	Context("when the routine cannot be executed in a transaction", func() {
+		It("executes the routine", func() {
+			_, err := provider.Exec(ctx, db, "vacuum")
+			Expect(err).To(Succeed())
+		})
+
+		It("returns an error for a transaction", func() {
+			tx, err := db.Beginx()
+			Expect(err).To(Succeed())
+
+			defer tx.Rollback()
+
+			_, err = provider.Exec(ctx, tx, "vacuum")
+			Expect(err).To(MatchError("query 'vacuum' cannot be executed in a transaction"))
+		})
+	})
+
+	Describe("NamedExec", func() {
+		It("executes the routine with a struct", func() {
+			_, err := provider.NamedExec(ctx, db, "insert-user", &User{ID: 3, Name: "john"})
+			Expect(err).To(Succeed())
+
+			user := User{}
+			Expect(provider.Get(ctx, db, &user, "select-user", 3)).To(Succeed())
+			Expect(user.Name).To(Equal("john"))
+		})
+
+		It("executes the routine with a map", func() {
+			param := map[string]interface{}{"id": 4, "name": "jane"}
+
+			_, err := provider.NamedExec(ctx, db, "insert-user", param)
+			Expect(err).To(Succeed())
+		})
+
+		Context("when the parameter is not a map or a struct", func() {
+			It("returns an error", func() {
+				_, err := provider.NamedExec(ctx, db, "insert-user", 1)
+				Expect(err).To(MatchError("query 'insert-user' expects a map or a struct of named parameters but got int"))
+			})
+		})
+	})
+})
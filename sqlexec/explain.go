@@ -0,0 +1,51 @@
+package sqlexec
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Explain returns the statement that explains the execution plan of a given
+// query for the dialect:
+//
+//	sqlite3   EXPLAIN QUERY PLAN
+//	postgres  EXPLAIN (FORMAT JSON) or EXPLAIN (ANALYZE, FORMAT JSON)
+//	mysql     EXPLAIN or EXPLAIN ANALYZE
+//
+// The query should contain a single statement. SQLite does not support
+// analyzing of the plan.
+func Explain(dialect, query string, analyze bool) (string, error) {
+	splitter := &Splitter{Dialect: dialect}
+	statements := splitter.Split(bytes.NewBufferString(query))
+
+	if len(statements) != 1 {
+		return "", fmt.Errorf("expected a single statement but got %d", len(statements))
+	}
+
+	statement := strings.TrimSpace(statements[0])
+	statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+
+	switch dialect {
+	case "sqlite3":
+		if analyze {
+			return "", fmt.Errorf("dialect '%s' does not support EXPLAIN ANALYZE", dialect)
+		}
+
+		return "EXPLAIN QUERY PLAN " + statement, nil
+	case "postgres":
+		if analyze {
+			return "EXPLAIN (ANALYZE, FORMAT JSON) " + statement, nil
+		}
+
+		return "EXPLAIN (FORMAT JSON) " + statement, nil
+	case "mysql":
+		if analyze {
+			return "EXPLAIN ANALYZE " + statement, nil
+		}
+
+		return "EXPLAIN " + statement, nil
+	default:
+		return "", fmt.Errorf("unsupported dialect '%s'", dialect)
+	}
+}
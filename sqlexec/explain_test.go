@@ -0,0 +1,59 @@
+package sqlexec_test
+
+import (
+	"github.com/phogolabs/prana/sqlexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Explain", func() {
+	query := "-- comment\nSELECT * FROM users WHERE id = ?;\n"
+
+	It("explains the query plan for sqlite3", func() {
+		statement, err := sqlexec.Explain("sqlite3", query, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statement).To(Equal("EXPLAIN QUERY PLAN -- comment\nSELECT * FROM users WHERE id = ?"))
+	})
+
+	It("explains the query in JSON format for postgres", func() {
+		statement, err := sqlexec.Explain("postgres", "SELECT 1", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statement).To(Equal("EXPLAIN (FORMAT JSON) SELECT 1"))
+
+		statement, err = sqlexec.Explain("postgres", "SELECT 1", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statement).To(Equal("EXPLAIN (ANALYZE, FORMAT JSON) SELECT 1"))
+	})
+
+	It("explains the query for mysql", func() {
+		statement, err := sqlexec.Explain("mysql", "SELECT 1;", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statement).To(Equal("EXPLAIN SELECT 1"))
+
+		statement, err = sqlexec.Explain("mysql", "SELECT 1;", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statement).To(Equal("EXPLAIN ANALYZE SELECT 1"))
+	})
+
+	Context("when the dialect is sqlite3 and the plan should be analyzed", func() {
+		It("returns an error", func() {
+			_, err := sqlexec.Explain("sqlite3", query, true)
+			Expect(err).To(MatchError("dialect 'sqlite3' does not support EXPLAIN ANALYZE"))
+		})
+	})
+
+	Context("when the query has multiple statements", func() {
+		It("returns an error", func() {
+			_, err := sqlexec.Explain("postgres", "SELECT 1; SELECT 2;", false)
+			Expect(err).To(MatchError("expected a single statement but got 2"))
+		})
+	})
+
+	Context("when the dialect is not supported", func() {
+		It("returns an error", func() {
+			_, err := sqlexec.Explain("oracle", "SELECT 1", false)
+			Expect(err).To(MatchError("unsupported dialect 'oracle'"))
+		})
+	})
+})
@@ -4,6 +4,7 @@ package sqlexec
 
 import (
 	"io/fs"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -12,6 +13,22 @@ var (
 	format = "20060102150405"
 )
 
+const (
+	// ReturnsOne is a routine that returns a single row
+	ReturnsOne = "one"
+	// ReturnsMany is a routine that returns multiple rows
+	ReturnsMany = "many"
+	// ReturnsExec is a routine that does not return rows
+	ReturnsExec = "exec"
+)
+
+const (
+	// TxRequired is a routine that should be executed in a transaction
+	TxRequired = "required"
+	// TxNone is a routine that should not be executed in a transaction
+	TxNone = "none"
+)
+
 // Param is a command parameter for given query.
 type Param = interface{}
 
@@ -29,3 +46,50 @@ type WriteFileSystem interface {
 	// OpenFile opens a new file
 	OpenFile(string, int, fs.FileMode) (fs.File, error)
 }
+
+// Routine represents a named SQL routine and its annotations.
+type Routine struct {
+	// Name of the routine. It's prefixed with the namespace if any.
+	Name string
+	// Namespace of the routine derived from its directory
+	Namespace string
+	// Dialect of the routine derived from its file name. It's empty for the
+	// portable routines.
+	Dialect string
+	// Doc is the documentation of the routine
+	Doc string
+	// Params are the declared parameters of the routine
+	Params []*RoutineParam
+	// Returns is the kind of the result (one, many or exec)
+	Returns string
+	// Timeout is the execution timeout of the routine
+	Timeout time.Duration
+	// Tx is the transaction mode of the routine (required or none)
+	Tx string
+	// Query is the body of the routine
+	Query string
+	// Tests are the declared test cases of the routine
+	Tests []*RoutineTest
+	// File is the path of the file that contains the routine
+	File string
+	// StartLine is the line of the routine's name tag
+	StartLine int
+	// EndLine is the last line of the routine's body
+	EndLine int
+}
+
+// RoutineParam represents a declared routine parameter.
+type RoutineParam struct {
+	// Name of the parameter
+	Name string
+	// Type of the parameter
+	Type string
+}
+
+// RoutineTest represents a declared test case of a routine.
+type RoutineTest struct {
+	// Params are the arguments of the routine
+	Params []*Arg
+	// Expect is the path to the golden file relative to the routine file
+	Expect string
+}
@@ -0,0 +1,315 @@
+package sqlexec
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// TypeNull is the type of NULL parameter
+	TypeNull = "null"
+	// TypeInt is the type of integer parameter
+	TypeInt = "int"
+	// TypeFloat is the type of floating point parameter
+	TypeFloat = "float"
+	// TypeBool is the type of boolean parameter
+	TypeBool = "bool"
+	// TypeTime is the type of time parameter
+	TypeTime = "time"
+	// TypeJSON is the type of JSON parameter
+	TypeJSON = "json"
+	// TypeString is the type of string parameter
+	TypeString = "string"
+)
+
+var (
+	argRgxp  = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*)?(?::([A-Za-z]+))?=(.*)$`)
+	nullRgxp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_.]*)?:(?i:null)$`)
+
+	timeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+	}
+)
+
+// ParamNames returns the names of the named parameters (e.g. :name) in the
+// order of their first occurrence. The string literals, the quoted
+// identifiers, the comments and the PostgreSQL type casts (e.g. ::int) are
+// skipped.
+func ParamNames(query string) []string {
+	var (
+		names   = []string{}
+		visited = map[string]bool{}
+	)
+
+	for _, param := range scanParams([]rune(query)) {
+		if !visited[param.Name] {
+			visited[param.Name] = true
+			names = append(names, param.Name)
+		}
+	}
+
+	return names
+}
+
+// BindNamed replaces the named parameters of the query with question marks
+// and returns the argument values in the same order. The argument should be
+// a map or a struct.
+func BindNamed(query string, arg Param) (string, []Param, error) {
+	var (
+		input  = []rune(query)
+		params = scanParams(input)
+		names  = []string{}
+		buffer = &strings.Builder{}
+		offset = 0
+	)
+
+	for _, param := range params {
+		buffer.WriteString(string(input[offset:param.Start]))
+		buffer.WriteString("?")
+		offset = param.End
+
+		names = append(names, ":"+param.Name)
+	}
+
+	buffer.WriteString(string(input[offset:]))
+
+	// sqlx resolves the values of the parameter list in the same order
+	_, args, err := sqlx.Named(strings.Join(names, ","), arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return buffer.String(), args, nil
+}
+
+type param struct {
+	Name  string
+	Start int
+	End   int
+}
+
+func scanParams(input []rune) []param {
+	params := []param{}
+
+	for index := 0; index < len(input); index++ {
+		char := input[index]
+
+		switch {
+		case char == '\'' || char == '"' || char == '`':
+			index = skipQuote(input, index, char)
+		case char == '-' && peek(input, index+1) == '-':
+			index = skipUntil(input, index, "\n")
+		case char == '/' && peek(input, index+1) == '*':
+			index = skipUntil(input, index+2, "*/")
+		case char == ':' && peek(input, index+1) == ':':
+			// type cast
+			index++
+		case char == ':' && isParamStart(peek(input, index+1)):
+			start := index
+			index++
+
+			for index < len(input) && isParamPart(input[index]) {
+				index++
+			}
+
+			params = append(params, param{
+				Name:  string(input[start+1 : index]),
+				Start: start,
+				End:   index,
+			})
+
+			index--
+		}
+	}
+
+	return params
+}
+
+// IsNamedParam returns true if the arguments should be bound by name. That
+// is the case for a single map or struct argument. The values that are
+// passed to the driver as they are (e.g. time.Time and driver.Valuer) are
+// positional.
+func IsNamedParam(args ...Param) bool {
+	if len(args) != 1 || args[0] == nil {
+		return false
+	}
+
+	switch args[0].(type) {
+	case time.Time, *time.Time, driver.Valuer:
+		return false
+	}
+
+	kind := reflect.Indirect(reflect.ValueOf(args[0])).Kind()
+	return kind == reflect.Map || kind == reflect.Struct
+}
+
+func skipQuote(input []rune, index int, quote rune) int {
+	for index++; index < len(input); index++ {
+		if input[index] != quote {
+			continue
+		}
+
+		// escaped quote
+		if peek(input, index+1) == quote {
+			index++
+			continue
+		}
+
+		return index
+	}
+
+	return index
+}
+
+func skipUntil(input []rune, index int, terminator string) int {
+	text := string(input[index:])
+
+	if position := strings.Index(text, terminator); position >= 0 {
+		return index + len([]rune(text[:position+len(terminator)])) - 1
+	}
+
+	return len(input)
+}
+
+func peek(input []rune, index int) rune {
+	if index < len(input) {
+		return input[index]
+	}
+
+	return 0
+}
+
+func isParamStart(char rune) bool {
+	return char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isParamPart(char rune) bool {
+	return isParamStart(char) || char == '.' || (char >= '0' && char <= '9')
+}
+
+// Arg is a command argument provided as text in the form of
+// [name][:type]=value or [name]:null.
+type Arg struct {
+	// Name of the argument. It's empty for positional arguments.
+	Name string
+	// Type of the argument. It's empty if the type is not provided.
+	Type string
+	// Value of the argument as text.
+	Value string
+}
+
+// ParseArg parses a command argument. The text that does not match the
+// argument form is a positional string argument.
+func ParseArg(text string) (*Arg, error) {
+	if match := nullRgxp.FindStringSubmatch(text); len(match) == 2 {
+		return &Arg{Name: match[1], Type: TypeNull}, nil
+	}
+
+	match := argRgxp.FindStringSubmatch(text)
+	if len(match) != 4 || match[1]+match[2] == "" {
+		return &Arg{Value: text}, nil
+	}
+
+	arg := &Arg{
+		Name:  match[1],
+		Type:  strings.ToLower(match[2]),
+		Value: match[3],
+	}
+
+	if arg.Type != "" && !isType(arg.Type) {
+		return nil, fmt.Errorf("unsupported parameter type '%s'", match[2])
+	}
+
+	return arg, nil
+}
+
+// Param returns the argument value converted to its type.
+func (arg *Arg) Param() (Param, error) {
+	switch arg.Type {
+	case TypeNull:
+		return nil, nil
+	case TypeInt:
+		value, err := strconv.ParseInt(arg.Value, 10, 64)
+
+		// the unsigned values can exceed the range of int64
+		if errors.Is(err, strconv.ErrRange) && !strings.HasPrefix(arg.Value, "-") {
+			return strconv.ParseUint(arg.Value, 10, 64)
+		}
+
+		return value, err
+	case TypeFloat:
+		return strconv.ParseFloat(arg.Value, 64)
+	case TypeBool:
+		return strconv.ParseBool(arg.Value)
+	case TypeTime:
+		for _, layout := range timeLayouts {
+			if value, err := time.Parse(layout, arg.Value); err == nil {
+				return value, nil
+			}
+		}
+
+		return nil, fmt.Errorf("cannot parse '%s' as time", arg.Value)
+	case TypeJSON:
+		if !json.Valid([]byte(arg.Value)) {
+			return nil, fmt.Errorf("cannot parse '%s' as json", arg.Value)
+		}
+
+		return arg.Value, nil
+	default:
+		return arg.Value, nil
+	}
+}
+
+// Args returns the positional parameters or a single map of the named
+// parameters of given arguments. The named and the positional arguments
+// cannot be mixed.
+func Args(args []*Arg) ([]Param, error) {
+	var (
+		named      = map[string]interface{}{}
+		positional = []Param{}
+	)
+
+	for _, arg := range args {
+		value, err := arg.Param()
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter '%s': %v", arg.Name, err)
+		}
+
+		if arg.Name == "" {
+			positional = append(positional, value)
+			continue
+		}
+
+		named[arg.Name] = value
+	}
+
+	switch {
+	case len(named) == 0:
+		return positional, nil
+	case len(positional) == 0:
+		return []Param{named}, nil
+	default:
+		return nil, fmt.Errorf("cannot mix named and positional parameters")
+	}
+}
+
+func isType(name string) bool {
+	switch name {
+	case TypeNull, TypeInt, TypeFloat, TypeBool, TypeTime, TypeJSON, TypeString:
+		return true
+	default:
+		return false
+	}
+}
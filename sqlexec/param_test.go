@@ -0,0 +1,229 @@
+package sqlexec_test
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/phogolabs/prana/sqlexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParamNames", func() {
+	It("returns the named parameters", func() {
+		names := sqlexec.ParamNames("SELECT * FROM users WHERE id = :id AND name = :name OR id = :id")
+		Expect(names).To(Equal([]string{"id", "name"}))
+	})
+
+	It("skips the string literals and quoted identifiers", func() {
+		names := sqlexec.ParamNames(`SELECT ':text', 'it''s :escaped', "col:umn", ` + "`mysql:col`" + ` FROM users WHERE id = :id`)
+		Expect(names).To(Equal([]string{"id"}))
+	})
+
+	It("skips the comments", func() {
+		names := sqlexec.ParamNames("-- :comment\nSELECT /* :block\n:comment */ * FROM users WHERE id = :id")
+		Expect(names).To(Equal([]string{"id"}))
+	})
+
+	It("skips the type casts", func() {
+		names := sqlexec.ParamNames("SELECT :id::int, created_at::date FROM users")
+		Expect(names).To(Equal([]string{"id"}))
+	})
+
+	Context("when the query has positional parameters", func() {
+		It("returns an empty list", func() {
+			Expect(sqlexec.ParamNames("SELECT * FROM users WHERE id = ?")).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("IsNamedParam", func() {
+	It("returns true for a map", func() {
+		Expect(sqlexec.IsNamedParam(map[string]interface{}{"id": 1})).To(BeTrue())
+	})
+
+	It("returns true for a struct", func() {
+		type user struct{ ID int }
+		Expect(sqlexec.IsNamedParam(&user{ID: 1})).To(BeTrue())
+	})
+
+	It("returns false for positional parameters", func() {
+		Expect(sqlexec.IsNamedParam("1", "2")).To(BeFalse())
+		Expect(sqlexec.IsNamedParam("1")).To(BeFalse())
+		Expect(sqlexec.IsNamedParam()).To(BeFalse())
+	})
+
+	It("returns false for a time", func() {
+		now := time.Now()
+		Expect(sqlexec.IsNamedParam(now)).To(BeFalse())
+		Expect(sqlexec.IsNamedParam(&now)).To(BeFalse())
+	})
+
+	It("returns false for a driver.Valuer", func() {
+		Expect(sqlexec.IsNamedParam(sql.NullString{String: "jack", Valid: true})).To(BeFalse())
+		Expect(sqlexec.IsNamedParam(&sql.NullInt64{Int64: 1, Valid: true})).To(BeFalse())
+	})
+})
+
+var _ = Describe("BindNamed", func() {
+	It("binds the named parameters", func() {
+		query, args, err := sqlexec.BindNamed("SELECT * FROM users WHERE id = :id AND name <> ':name' OR id = :id", map[string]interface{}{
+			"id": 1,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal("SELECT * FROM users WHERE id = ? AND name <> ':name' OR id = ?"))
+		Expect(args).To(Equal([]interface{}{1, 1}))
+	})
+
+	Context("when the parameter is missing", func() {
+		It("returns an error", func() {
+			_, _, err := sqlexec.BindNamed("SELECT * FROM users WHERE id = :id", map[string]interface{}{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("ParseArg", func() {
+	It("parses a positional argument", func() {
+		arg, err := sqlexec.ParseArg("hello")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(arg).To(Equal(&sqlexec.Arg{Value: "hello"}))
+	})
+
+	It("parses a named argument", func() {
+		arg, err := sqlexec.ParseArg("name=john=doe")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(arg).To(Equal(&sqlexec.Arg{Name: "name", Value: "john=doe"}))
+	})
+
+	It("parses a typed argument", func() {
+		arg, err := sqlexec.ParseArg("id:int=42")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(arg).To(Equal(&sqlexec.Arg{Name: "id", Type: "int", Value: "42"}))
+	})
+
+	It("parses a typed positional argument", func() {
+		arg, err := sqlexec.ParseArg(":bool=true")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(arg).To(Equal(&sqlexec.Arg{Type: "bool", Value: "true"}))
+	})
+
+	It("parses a null argument", func() {
+		arg, err := sqlexec.ParseArg("deleted_at:null")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(arg).To(Equal(&sqlexec.Arg{Name: "deleted_at", Type: "null"}))
+	})
+
+	Context("when the type is not supported", func() {
+		It("returns an error", func() {
+			_, err := sqlexec.ParseArg("id:integer=42")
+			Expect(err).To(MatchError("unsupported parameter type 'integer'"))
+		})
+	})
+})
+
+var _ = Describe("Arg", func() {
+	It("converts the value to string", func() {
+		arg := &sqlexec.Arg{Value: "42"}
+		Expect(arg.Param()).To(Equal("42"))
+	})
+
+	It("converts the value to int", func() {
+		arg := &sqlexec.Arg{Type: sqlexec.TypeInt, Value: "42"}
+		Expect(arg.Param()).To(Equal(int64(42)))
+	})
+
+	It("converts the value to unsigned int when it exceeds int64", func() {
+		arg := &sqlexec.Arg{Type: sqlexec.TypeInt, Value: "18446744073709551615"}
+		Expect(arg.Param()).To(Equal(uint64(18446744073709551615)))
+	})
+
+	It("converts the value to float", func() {
+		arg := &sqlexec.Arg{Type: sqlexec.TypeFloat, Value: "4.2"}
+		Expect(arg.Param()).To(Equal(4.2))
+	})
+
+	It("converts the value to bool", func() {
+		arg := &sqlexec.Arg{Type: sqlexec.TypeBool, Value: "true"}
+		Expect(arg.Param()).To(BeTrue())
+	})
+
+	It("converts the value to time", func() {
+		arg := &sqlexec.Arg{Type: sqlexec.TypeTime, Value: "2006-01-02"}
+		Expect(arg.Param()).To(Equal(time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("converts the value to json", func() {
+		arg := &sqlexec.Arg{Type: sqlexec.TypeJSON, Value: `{"id":1}`}
+		Expect(arg.Param()).To(Equal(`{"id":1}`))
+	})
+
+	It("converts the value to null", func() {
+		arg := &sqlexec.Arg{Type: sqlexec.TypeNull}
+		Expect(arg.Param()).To(BeNil())
+	})
+
+	Context("when the value cannot be converted", func() {
+		It("returns an error", func() {
+			for kind, value := range map[string]string{
+				sqlexec.TypeInt:   "forty",
+				sqlexec.TypeFloat: "x",
+				sqlexec.TypeBool:  "maybe",
+				sqlexec.TypeTime:  "yesterday",
+				sqlexec.TypeJSON:  "{",
+			} {
+				arg := &sqlexec.Arg{Type: kind, Value: value}
+
+				_, err := arg.Param()
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+})
+
+var _ = Describe("Args", func() {
+	It("returns the positional parameters", func() {
+		args, err := sqlexec.Args([]*sqlexec.Arg{
+			{Value: "jack"},
+			{Type: "int", Value: "42"},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]sqlexec.Param{"jack", int64(42)}))
+	})
+
+	It("returns a map of the named parameters", func() {
+		args, err := sqlexec.Args([]*sqlexec.Arg{
+			{Name: "name", Value: "jack"},
+			{Name: "age", Type: "int", Value: "42"},
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(Equal([]sqlexec.Param{
+			map[string]interface{}{"name": "jack", "age": int64(42)},
+		}))
+	})
+
+	Context("when the named and the positional parameters are mixed", func() {
+		It("returns an error", func() {
+			_, err := sqlexec.Args([]*sqlexec.Arg{
+				{Name: "name", Value: "jack"},
+				{Value: "42"},
+			})
+
+			Expect(err).To(MatchError("cannot mix named and positional parameters"))
+		})
+	})
+
+	Context("when a value cannot be converted", func() {
+		It("returns an error", func() {
+			_, err := sqlexec.Args([]*sqlexec.Arg{
+				{Name: "age", Type: "int", Value: "old"},
+			})
+
+			Expect(err).To(MatchError(`invalid parameter 'age': strconv.ParseInt: parsing "old": invalid syntax`))
+		})
+	})
+})
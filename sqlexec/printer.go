@@ -0,0 +1,388 @@
+package sqlexec
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	// FormatTable prints the rows as ASCII table
+	FormatTable = "table"
+	// FormatJSON prints the rows as JSON array of objects
+	FormatJSON = "json"
+	// FormatNDJSON prints the rows as newline delimited JSON objects
+	FormatNDJSON = "ndjson"
+	// FormatCSV prints the rows as comma separated values
+	FormatCSV = "csv"
+	// FormatTSV prints the rows as tab separated values
+	FormatTSV = "tsv"
+	// FormatYAML prints the rows as YAML sequence of mappings
+	FormatYAML = "yaml"
+	// FormatMarkdown prints the rows as Markdown table
+	FormatMarkdown = "markdown"
+)
+
+const (
+	// BinaryText prints the byte slices as text
+	BinaryText = "text"
+	// BinaryHex prints the byte slices as hex string
+	BinaryHex = "hex"
+	// BinaryBase64 prints the byte slices as base64 string
+	BinaryBase64 = "base64"
+)
+
+var keyRgxp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// csvNull represents NULL value in CSV and TSV output
+const csvNull = `\N`
+
+// records calls given function for every record in the order they are read
+type records func(fn func(record []interface{}) error) error
+
+// Printer prints the rows in a given format. The rows are printed as they are
+// read except for the table format, which needs all of them to align the
+// columns.
+type Printer struct {
+	// Format of the output. Default to table.
+	Format string
+	// Binary is the encoding of the binary values. Default to text.
+	Binary string
+}
+
+// Print prints the rows
+func (p *Printer) Print(writer io.Writer, rows *Rows) error {
+	switch strings.ToLower(p.Binary) {
+	case "", BinaryText, BinaryHex, BinaryBase64:
+	default:
+		return fmt.Errorf("unsupported binary encoding '%s'", p.Binary)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	each := func(fn func(record []interface{}) error) error {
+		for rows.Next() {
+			record, err := rows.SliceScan()
+			if err != nil {
+				return err
+			}
+
+			for index, value := range record {
+				record[index] = p.value(types[index], value)
+			}
+
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	}
+
+	return p.render(writer, columns, each)
+}
+
+// PrintResults prints the results of executed statements
+func (p *Printer) PrintResults(writer io.Writer, results []*Result) error {
+	columns := []string{"statement", "rows_affected", "last_insert_id"}
+
+	each := func(fn func(record []interface{}) error) error {
+		for index, result := range results {
+			if err := fn([]interface{}{index + 1, result.RowsAffected, result.LastInsertID}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return p.render(writer, columns, each)
+}
+
+func (p *Printer) render(writer io.Writer, columns []string, each records) error {
+	switch strings.ToLower(p.Format) {
+	case "", FormatTable:
+		return p.table(writer, columns, each)
+	case FormatJSON:
+		return p.json(writer, columns, each)
+	case FormatNDJSON:
+		return p.ndjson(writer, columns, each)
+	case FormatCSV:
+		return p.csv(writer, ',', columns, each)
+	case FormatTSV:
+		return p.csv(writer, '\t', columns, each)
+	case FormatYAML:
+		return p.yaml(writer, columns, each)
+	case FormatMarkdown:
+		return p.markdown(writer, columns, each)
+	default:
+		return fmt.Errorf("unsupported format '%s'", p.Format)
+	}
+}
+
+func (p *Printer) value(kind *sql.ColumnType, value interface{}) interface{} {
+	data, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+
+	if !p.binary(kind, data) {
+		return string(data)
+	}
+
+	switch strings.ToLower(p.Binary) {
+	case BinaryHex:
+		return hex.EncodeToString(data)
+	case BinaryBase64:
+		return base64.StdEncoding.EncodeToString(data)
+	default:
+		return string(data)
+	}
+}
+
+func (p *Printer) binary(kind *sql.ColumnType, data []byte) bool {
+	if !utf8.Valid(data) {
+		return true
+	}
+
+	name := strings.ToUpper(kind.DatabaseTypeName())
+	return strings.Contains(name, "BLOB") || strings.Contains(name, "BINARY") || name == "BYTEA"
+}
+
+func (p *Printer) table(writer io.Writer, columns []string, each records) error {
+	table := tablewriter.NewWriter(writer)
+	table.Header(columns)
+
+	err := each(func(record []interface{}) error {
+		return table.Append(p.strings(record, "NULL"))
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return table.Render()
+}
+
+func (p *Printer) json(writer io.Writer, columns []string, each records) error {
+	if _, err := io.WriteString(writer, "["); err != nil {
+		return err
+	}
+
+	count := 0
+
+	err := each(func(record []interface{}) error {
+		if count > 0 {
+			if _, err := io.WriteString(writer, ","); err != nil {
+				return err
+			}
+		}
+
+		count++
+		return p.object(writer, columns, record)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(writer, "]\n")
+	return err
+}
+
+func (p *Printer) ndjson(writer io.Writer, columns []string, each records) error {
+	return each(func(record []interface{}) error {
+		if err := p.object(writer, columns, record); err != nil {
+			return err
+		}
+
+		_, err := io.WriteString(writer, "\n")
+		return err
+	})
+}
+
+// object writes the record as JSON object that keeps the order of the columns
+func (p *Printer) object(writer io.Writer, columns []string, record []interface{}) error {
+	buffer := &strings.Builder{}
+	buffer.WriteString("{")
+
+	for index, column := range columns {
+		if index > 0 {
+			buffer.WriteString(",")
+		}
+
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(record[index])
+		if err != nil {
+			return err
+		}
+
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+
+	buffer.WriteString("}")
+
+	_, err := io.WriteString(writer, buffer.String())
+	return err
+}
+
+// csv writes the records as comma or tab separated values. Every record is
+// flushed as it's written. The NULL values are written as \N.
+func (p *Printer) csv(writer io.Writer, comma rune, columns []string, each records) error {
+	encoder := csv.NewWriter(writer)
+	encoder.Comma = comma
+
+	if err := encoder.Write(columns); err != nil {
+		return err
+	}
+
+	err := each(func(record []interface{}) error {
+		if err := encoder.Write(p.strings(record, csvNull)); err != nil {
+			return err
+		}
+
+		encoder.Flush()
+		return encoder.Error()
+	})
+
+	if err != nil {
+		return err
+	}
+
+	encoder.Flush()
+	return encoder.Error()
+}
+
+func (p *Printer) yaml(writer io.Writer, columns []string, each records) error {
+	count := 0
+
+	err := each(func(record []interface{}) error {
+		buffer := &strings.Builder{}
+
+		for index, column := range columns {
+			prefix := "  "
+			if index == 0 {
+				prefix = "- "
+			}
+
+			value, err := p.scalar(record[index])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(buffer, "%s%s: %s\n", prefix, p.key(column), value)
+		}
+
+		count++
+
+		_, err := io.WriteString(writer, buffer.String())
+		return err
+	})
+
+	if err != nil || count > 0 {
+		return err
+	}
+
+	_, err = io.WriteString(writer, "[]\n")
+	return err
+}
+
+func (p *Printer) key(name string) string {
+	if keyRgxp.MatchString(name) {
+		return name
+	}
+
+	data, _ := json.Marshal(name)
+	return string(data)
+}
+
+// scalar returns the value as YAML scalar. The JSON strings are valid YAML
+// double-quoted scalars.
+func (p *Printer) scalar(value interface{}) (string, error) {
+	if value == nil {
+		return "null", nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func (p *Printer) markdown(writer io.Writer, columns []string, each records) error {
+	row := func(values []string) error {
+		buffer := &strings.Builder{}
+		buffer.WriteString("|")
+
+		for _, value := range values {
+			value = strings.Replace(value, "|", "\\|", -1)
+			value = strings.Replace(value, "\n", "<br>", -1)
+			fmt.Fprintf(buffer, " %s |", value)
+		}
+
+		buffer.WriteString("\n")
+
+		_, err := io.WriteString(writer, buffer.String())
+		return err
+	}
+
+	separator := make([]string, len(columns))
+	for index := range separator {
+		separator[index] = "---"
+	}
+
+	if err := row(columns); err != nil {
+		return err
+	}
+
+	if err := row(separator); err != nil {
+		return err
+	}
+
+	return each(func(record []interface{}) error {
+		return row(p.strings(record, "NULL"))
+	})
+}
+
+func (p *Printer) strings(record []interface{}, null string) []string {
+	values := []string{}
+
+	for _, value := range record {
+		switch item := value.(type) {
+		case nil:
+			values = append(values, null)
+		case time.Time:
+			values = append(values, item.Format(time.RFC3339Nano))
+		default:
+			values = append(values, fmt.Sprintf("%v", item))
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,149 @@
+package sqlexec_test
+
+import (
+	"bytes"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Printer", func() {
+	var (
+		db      *sqlx.DB
+		printer *sqlexec.Printer
+		writer  *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		db, err = sqlx.Open("sqlite3", ":memory:")
+		Expect(err).NotTo(HaveOccurred())
+
+		printer = &sqlexec.Printer{}
+		writer = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	print := func() string {
+		rows, err := db.Queryx("SELECT 1 AS id, 'John | \"Doe\"' AS name, NULL AS deleted_at, x'cafe' AS data")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(printer.Print(writer, rows)).To(Succeed())
+		return writer.String()
+	}
+
+	It("prints the rows as table", func() {
+		content := print()
+		Expect(content).To(ContainSubstring("DELETED AT"))
+		Expect(content).To(ContainSubstring("NULL"))
+		Expect(content).NotTo(ContainSubstring("<nil>"))
+	})
+
+	It("prints the rows as json", func() {
+		printer.Format = sqlexec.FormatJSON
+		printer.Binary = sqlexec.BinaryBase64
+		Expect(print()).To(Equal(`[{"id":1,"name":"John | \"Doe\"","deleted_at":null,"data":"yv4="}]` + "\n"))
+	})
+
+	It("prints the rows as ndjson", func() {
+		printer.Format = sqlexec.FormatNDJSON
+		printer.Binary = sqlexec.BinaryHex
+		Expect(print()).To(Equal(`{"id":1,"name":"John | \"Doe\"","deleted_at":null,"data":"cafe"}` + "\n"))
+	})
+
+	It("prints the rows as csv", func() {
+		printer.Format = sqlexec.FormatCSV
+		printer.Binary = sqlexec.BinaryBase64
+		Expect(print()).To(Equal("id,name,deleted_at,data\n1,\"John | \"\"Doe\"\"\",\\N,yv4=\n"))
+	})
+
+	It("prints the rows as tsv", func() {
+		printer.Format = sqlexec.FormatTSV
+		printer.Binary = sqlexec.BinaryHex
+		Expect(print()).To(Equal("id\tname\tdeleted_at\tdata\n1\t\"John | \"\"Doe\"\"\"\t\\N\tcafe\n"))
+	})
+
+	It("prints the rows as yaml", func() {
+		printer.Format = sqlexec.FormatYAML
+		printer.Binary = sqlexec.BinaryHex
+		Expect(print()).To(Equal("- id: 1\n  name: \"John | \\\"Doe\\\"\"\n  deleted_at: null\n  data: \"cafe\"\n"))
+	})
+
+	It("prints the rows as markdown", func() {
+		printer.Format = sqlexec.FormatMarkdown
+		printer.Binary = sqlexec.BinaryHex
+		Expect(print()).To(Equal("| id | name | deleted_at | data |\n| --- | --- | --- | --- |\n| 1 | John \\| \"Doe\" | NULL | cafe |\n"))
+	})
+
+	Describe("PrintResults", func() {
+		results := []*sqlexec.Result{
+			{Statement: "DELETE FROM users", RowsAffected: 2},
+			{Statement: "INSERT INTO users (name) VALUES ('Jack')", RowsAffected: 1, LastInsertID: 3},
+		}
+
+		It("prints the results as table", func() {
+			Expect(printer.PrintResults(writer, results)).To(Succeed())
+			Expect(writer.String()).To(ContainSubstring("ROWS AFFECTED"))
+			Expect(writer.String()).To(ContainSubstring("LAST INSERT ID"))
+		})
+
+		It("prints the results in the format", func() {
+			printer.Format = sqlexec.FormatNDJSON
+
+			Expect(printer.PrintResults(writer, results)).To(Succeed())
+			Expect(writer.String()).To(Equal(
+				`{"statement":1,"rows_affected":2,"last_insert_id":0}` + "\n" +
+					`{"statement":2,"rows_affected":1,"last_insert_id":3}` + "\n"))
+		})
+	})
+
+	Context("when a row cannot be read", func() {
+		It("prints the rows that have been read", func() {
+			printer.Format = sqlexec.FormatNDJSON
+
+			rows, err := db.Queryx("WITH t(id) AS (VALUES (1), (2)) SELECT id, CASE WHEN id = 2 THEN json('{') END AS data FROM t")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(printer.Print(writer, rows)).To(MatchError(ContainSubstring("malformed JSON")))
+			Expect(writer.String()).To(Equal(`{"id":1,"data":null}` + "\n"))
+		})
+	})
+
+	Context("when there are no rows", func() {
+		It("prints an empty yaml sequence", func() {
+			printer.Format = sqlexec.FormatYAML
+
+			rows, err := db.Queryx("SELECT 1 AS id WHERE 1 = 0")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(printer.Print(writer, rows)).To(Succeed())
+			Expect(writer.String()).To(Equal("[]\n"))
+		})
+	})
+
+	Context("when the format is not supported", func() {
+		It("returns an error", func() {
+			printer.Format = "xml"
+
+			rows, err := db.Queryx("SELECT 1 AS id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(printer.Print(writer, rows)).To(MatchError("unsupported format 'xml'"))
+		})
+	})
+	Context("when the binary encoding is not supported", func() {
+		It("returns an error", func() {
+			printer.Binary = "base32"
+
+			rows, err := db.Queryx("SELECT 1 AS id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(printer.Print(writer, rows)).To(MatchError("unsupported binary encoding 'base32'"))
+		})
+	})
+})
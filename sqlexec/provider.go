@@ -1,13 +1,17 @@
 package sqlexec
 
 import (
+	"context"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -18,7 +22,7 @@ const every = "sql"
 type Provider struct {
 	dialect    string
 	mu         sync.RWMutex
-	repository map[string]string
+	repository map[string]*Routine
 }
 
 // Dialect returns the dialect
@@ -64,7 +68,13 @@ func (p *Provider) ReadFile(path string, storage FileSystem) error {
 		}
 	}()
 
-	if _, err = p.ReadFrom(file); err != nil {
+	dialect := PathDriver(path)
+
+	if dialect == every {
+		dialect = ""
+	}
+
+	if _, err = p.read(file, path, dialect); err != nil {
 		return err
 	}
 
@@ -73,38 +83,243 @@ func (p *Provider) ReadFile(path string, storage FileSystem) error {
 
 // ReadFrom reads the sqlexec from a reader
 func (p *Provider) ReadFrom(r io.Reader) (int64, error) {
+	return p.read(r, "", "")
+}
+
+// read reads the routines from a file and prefixes their names with the
+// namespace of the file. The routines of a dialect override the portable
+// routines with the same name regardless of the order in which they are read.
+func (p *Provider) read(r io.Reader, path, dialect string) (int64, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	if p.repository == nil {
-		p.repository = make(map[string]string)
+		p.repository = make(map[string]*Routine)
 	}
 
 	scanner := &Scanner{}
-	stmts := scanner.Scan(r)
 
-	for name, stmt := range stmts {
-		if _, ok := p.repository[name]; ok {
-			return 0, fmt.Errorf("query '%s' already exists", name)
+	routines, err := scanner.ScanRoutines(r)
+	if err != nil {
+		return 0, err
+	}
+
+	namespace := PathNamespace(path)
+
+	for _, routine := range routines {
+		routine.File = path
+
+		if namespace != "" {
+			routine.Namespace = namespace
+			routine.Name = namespace + "." + routine.Name
 		}
 
-		p.repository[name] = stmt
+		routine.Dialect = dialect
+		name := routine.Name
+
+		if existing, ok := p.repository[name]; ok {
+			switch {
+			case existing.Dialect == routine.Dialect:
+				return 0, fmt.Errorf("query '%s' already exists", name)
+			case existing.Dialect != "":
+				// the dialect routine overrides the portable one
+				continue
+			}
+		}
+
+		p.repository[name] = routine
+	}
+
+	return int64(len(routines)), nil
+}
+
+// Reload reads all routines from a given directory and replaces the loaded
+// routines atomically. The loaded routines are kept if the directory cannot
+// be read or a routine cannot be parsed.
+func (p *Provider) Reload(storage FileSystem) error {
+	provider := &Provider{
+		dialect: p.Dialect(),
 	}
 
-	return int64(len(stmts)), nil
+	if err := provider.ReadDir(storage); err != nil {
+		return err
+	}
+
+	if provider.repository == nil {
+		provider.repository = make(map[string]*Routine)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.repository = provider.repository
+	return nil
+}
+
+// Watch polls a given directory for changes at every interval and reloads
+// the routines when the content of the SQL files changes. The reload errors
+// are sent to the returned channel, which should be drained by the caller.
+// The channel is closed when the context is done.
+func (p *Provider) Watch(ctx context.Context, storage FileSystem, interval time.Duration) <-chan error {
+	errs := make(chan error)
+	checksum, _ := p.checksum(storage)
+
+	go func() {
+		defer close(errs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := p.checksum(storage)
+
+			if err == nil {
+				if current == checksum {
+					continue
+				}
+
+				checksum = current
+				err = p.Reload(storage)
+			}
+
+			if err == nil {
+				continue
+			}
+
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return errs
+}
+
+// checksum returns the checksum of the paths and the content of the SQL files
+// in a given directory
+func (p *Provider) checksum(storage FileSystem) (uint64, error) {
+	hash := fnv.New64a()
+
+	err := fs.WalkDir(storage, ".", func(path string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !p.filter(path) {
+			return nil
+		}
+
+		data, err := fs.ReadFile(storage, path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(hash, "%s:%d:", path, len(data))
+		_, err = hash.Write(data)
+		return err
+	})
+
+	return hash.Sum64(), err
 }
 
 // Query returns a query statement for given name and parameters. The operation can
 // err if the command cannot be found.
 func (p *Provider) Query(name string) (string, error) {
+	routine, err := p.Routine(name)
+	if err != nil {
+		return "", err
+	}
+
+	return sqlx.Rebind(sqlx.BindType(p.dialect), routine.Query), nil
+}
+
+// Render returns the query and the positional arguments of a given routine.
+// The templated routines are rendered with the parameter (see Render). The
+// other routines bind the parameter by name if it is a map or a struct. The
+// operation can err if the command cannot be found.
+func (p *Provider) Render(name string, param Param) (string, []Param, error) {
+	routine, err := p.Routine(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if IsTemplate(routine.Query) {
+		return Render(p.dialect, routine.Query, param)
+	}
+
+	query := routine.Query
+	args := []Param{}
+
+	if param != nil {
+		if query, args, err = BindNamed(query, param); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return sqlx.Rebind(sqlx.BindType(p.dialect), query), args, nil
+}
+
+// Routine returns the routine for given name. The name can be fully-qualified
+// (e.g. billing.get-user) or a short name that is unique across the
+// namespaces (e.g. get-user). The operation can err if the routine cannot be
+// found or the short name is ambiguous.
+func (p *Provider) Routine(name string) (*Routine, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if routine, ok := p.repository[name]; ok {
+		return routine, nil
+	}
+
+	matches := []*Routine{}
+
+	for _, routine := range p.repository {
+		if routine.Namespace != "" && routine.Name == routine.Namespace+"."+name {
+			matches = append(matches, routine)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, nonExistQueryErr(name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := []string{}
+
+		for _, routine := range matches {
+			names = append(names, routine.Name)
+		}
+
+		sort.Strings(names)
+		return nil, fmt.Errorf("query '%s' is ambiguous: %s", name, strings.Join(names, ", "))
+	}
+}
+
+// Routines returns all routines ordered by their name.
+func (p *Provider) Routines() []*Routine {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	if query, ok := p.repository[name]; ok {
-		return sqlx.Rebind(sqlx.BindType(p.dialect), query), nil
+	routines := []*Routine{}
+
+	for _, routine := range p.repository {
+		routines = append(routines, routine)
 	}
 
-	return "", nonExistQueryErr(name)
+	sort.Slice(routines, func(i, j int) bool {
+		return routines[i].Name < routines[j].Name
+	})
+
+	return routines
 }
 
 // Filter returns true if the file can be processed for the current driver
@@ -119,6 +334,18 @@ func (p *Provider) filter(path string) bool {
 	return driver == every || driver == p.dialect
 }
 
+// PathNamespace returns the namespace from the directory of a given path
+// (e.g. billing/users.sql is in billing namespace)
+func PathNamespace(path string) string {
+	dir := filepath.ToSlash(filepath.Dir(path))
+
+	if dir == "." || dir == "/" {
+		return ""
+	}
+
+	return strings.Replace(strings.Trim(dir, "/"), "/", ".", -1)
+}
+
 // PathDriver returns the driver name from a given path
 func PathDriver(path string) string {
 	ext := filepath.Ext(path)
@@ -2,8 +2,13 @@ package sqlexec_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing/fstest"
+	"time"
 
 	"github.com/phogolabs/prana/sqlexec"
 
@@ -54,6 +59,103 @@ var _ = Describe("Provider", func() {
 		})
 	})
 
+	Describe("Routine", func() {
+		BeforeEach(func() {
+			buffer := &bytes.Buffer{}
+			fmt.Fprintln(buffer, "-- name: select-users")
+			fmt.Fprintln(buffer, "-- doc: Returns all users")
+			fmt.Fprintln(buffer, "-- returns: many")
+			fmt.Fprintln(buffer, "SELECT * FROM users;")
+			fmt.Fprintln(buffer)
+			fmt.Fprintln(buffer, "-- name: delete-users")
+			fmt.Fprintln(buffer, "DELETE FROM users;")
+
+			_, err := provider.ReadFrom(buffer)
+			Expect(err).To(Succeed())
+		})
+
+		It("returns the routine", func() {
+			routine, err := provider.Routine("select-users")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routine.Doc).To(Equal("Returns all users"))
+			Expect(routine.Returns).To(Equal(sqlexec.ReturnsMany))
+			Expect(routine.Query).To(Equal("SELECT * FROM users;"))
+		})
+
+		It("returns all routines ordered by name", func() {
+			routines := provider.Routines()
+			Expect(routines).To(HaveLen(2))
+			Expect(routines[0].Name).To(Equal("delete-users"))
+			Expect(routines[1].Name).To(Equal("select-users"))
+		})
+
+		Context("when the routine does not exist", func() {
+			It("returns an error", func() {
+				_, err := provider.Routine("update-users")
+				Expect(err).To(MatchError("query 'update-users' not found"))
+			})
+		})
+
+		Context("when the routine has invalid annotation", func() {
+			It("returns an error", func() {
+				buffer := &bytes.Buffer{}
+				fmt.Fprintln(buffer, "-- name: update-users")
+				fmt.Fprintln(buffer, "-- tx: maybe")
+				fmt.Fprintln(buffer, "UPDATE users SET name = '';")
+
+				_, err := provider.ReadFrom(buffer)
+				Expect(err).To(MatchError("routine 'update-users': invalid tx annotation 'maybe'"))
+			})
+		})
+	})
+
+	Describe("Render", func() {
+		BeforeEach(func() {
+			buffer := &bytes.Buffer{}
+			fmt.Fprintln(buffer, "-- name: search-users")
+			fmt.Fprintln(buffer, "SELECT * FROM users WHERE 1 = 1{{if .name}} AND name = {{bind .name}}{{end}}")
+			fmt.Fprintln(buffer)
+			fmt.Fprintln(buffer, "-- name: select-user")
+			fmt.Fprintln(buffer, "SELECT * FROM users WHERE id = :id")
+			fmt.Fprintln(buffer)
+			fmt.Fprintln(buffer, "-- name: select-matrix")
+			fmt.Fprintln(buffer, "SELECT * FROM matrices WHERE value = '{{1,2},{3,4}}' AND id = :id")
+
+			provider.SetDialect("postgres")
+
+			_, err := provider.ReadFrom(buffer)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("renders the templated routine", func() {
+			query, args, err := provider.Render("search-users", map[string]interface{}{"name": "John"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal("SELECT * FROM users WHERE 1 = 1 AND name = $1"))
+			Expect(args).To(Equal([]interface{}{"John"}))
+		})
+
+		It("binds the named parameters of the routine", func() {
+			query, args, err := provider.Render("select-user", map[string]interface{}{"id": 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal("SELECT * FROM users WHERE id = $1"))
+			Expect(args).To(Equal([]interface{}{1}))
+		})
+
+		It("does not render the braces of the literals", func() {
+			query, args, err := provider.Render("select-matrix", map[string]interface{}{"id": 1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal("SELECT * FROM matrices WHERE value = '{{1,2},{3,4}}' AND id = $1"))
+			Expect(args).To(Equal([]interface{}{1}))
+		})
+
+		Context("when the routine does not exist", func() {
+			It("returns an error", func() {
+				_, _, err := provider.Render("unknown", nil)
+				Expect(err).To(MatchError("query 'unknown' not found"))
+			})
+		})
+	})
+
 	Describe("ReadDir", func() {
 		var storage fstest.MapFS
 
@@ -77,6 +179,108 @@ var _ = Describe("Provider", func() {
 			Expect(query).To(Equal("SELECT * FROM categories;"))
 		})
 
+		Context("when a routine is defined for the dialect", func() {
+			BeforeEach(func() {
+				provider.SetDialect("sqlite3")
+
+				storage["routine.sql"] = &fstest.MapFile{
+					Data: []byte("-- name: select-users\nSELECT * FROM users;\n"),
+				}
+
+				storage["routine_postgres.sql"] = &fstest.MapFile{
+					Data: []byte("-- name: select-users\nSELECT * FROM public.users;\n"),
+				}
+			})
+
+			It("overrides the portable routine", func() {
+				storage["routine_sqlite3.sql"] = &fstest.MapFile{
+					Data: []byte("-- name: select-users\nSELECT * FROM main.users;\n"),
+				}
+
+				Expect(provider.ReadDir(storage)).To(Succeed())
+
+				routine, err := provider.Routine("select-users")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(routine.Query).To(Equal("SELECT * FROM main.users;"))
+				Expect(routine.Dialect).To(Equal("sqlite3"))
+			})
+
+			It("overrides the portable routine that is read after it", func() {
+				storage["a_sqlite3.sql"] = &fstest.MapFile{
+					Data: []byte("-- name: select-users\nSELECT * FROM main.users;\n"),
+				}
+
+				Expect(provider.ReadDir(storage)).To(Succeed())
+
+				query, err := provider.Query("select-users")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(query).To(Equal("SELECT * FROM main.users;"))
+			})
+
+			Context("when the routine is defined twice for the dialect", func() {
+				It("returns an error", func() {
+					storage["a_sqlite3.sql"] = &fstest.MapFile{
+						Data: []byte("-- name: select-users\nSELECT * FROM main.users;\n"),
+					}
+
+					storage["b_sqlite3.sql"] = &fstest.MapFile{
+						Data: []byte("-- name: select-users\nSELECT * FROM main.users;\n"),
+					}
+
+					Expect(provider.ReadDir(storage)).To(MatchError("query 'select-users' already exists"))
+				})
+			})
+		})
+
+		Context("when the routines are in subdirectories", func() {
+			BeforeEach(func() {
+				storage["billing/users.sql"] = &fstest.MapFile{
+					Data: []byte("-- name: get-user\nSELECT * FROM billing_users;\n"),
+				}
+
+				storage["auth/users.sql"] = &fstest.MapFile{
+					Data: []byte("-- name: get-user\nSELECT * FROM auth_users;\n\n-- name: get-session\nSELECT * FROM sessions;\n"),
+				}
+
+				storage["auth/oauth/clients.sql"] = &fstest.MapFile{
+					Data: []byte("-- name: get-client\nSELECT * FROM clients;\n"),
+				}
+			})
+
+			It("prefixes the routines with their namespace", func() {
+				Expect(provider.ReadDir(storage)).To(Succeed())
+
+				query, err := provider.Query("billing.get-user")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(query).To(Equal("SELECT * FROM billing_users;"))
+
+				routine, err := provider.Routine("auth.oauth.get-client")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(routine.Name).To(Equal("auth.oauth.get-client"))
+				Expect(routine.Namespace).To(Equal("auth.oauth"))
+				Expect(routine.File).To(Equal("auth/oauth/clients.sql"))
+				Expect(routine.StartLine).To(Equal(1))
+				Expect(routine.EndLine).To(Equal(2))
+			})
+
+			It("finds the routine by its unique short name", func() {
+				Expect(provider.ReadDir(storage)).To(Succeed())
+
+				query, err := provider.Query("get-session")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(query).To(Equal("SELECT * FROM sessions;"))
+			})
+
+			Context("when the short name is ambiguous", func() {
+				It("returns an error", func() {
+					Expect(provider.ReadDir(storage)).To(Succeed())
+
+					_, err := provider.Query("get-user")
+					Expect(err).To(MatchError("query 'get-user' is ambiguous: auth.get-user, billing.get-user"))
+				})
+			})
+		})
+
 		It("skips none sql files", func() {
 			buffer := &bytes.Buffer{}
 			fmt.Fprintln(buffer, "-- name: get-categories")
@@ -93,4 +297,104 @@ var _ = Describe("Provider", func() {
 			Expect(query).To(BeEmpty())
 		})
 	})
+
+	Describe("Reload", func() {
+		var storage fstest.MapFS
+
+		BeforeEach(func() {
+			storage = fstest.MapFS{
+				"routine.sql": &fstest.MapFile{
+					Data: []byte("-- name: get-categories\nSELECT * FROM categories;\n"),
+				},
+			}
+
+			Expect(provider.ReadDir(storage)).To(Succeed())
+		})
+
+		It("replaces the routines", func() {
+			storage["routine.sql"] = &fstest.MapFile{
+				Data: []byte("-- name: get-products\nSELECT * FROM products;\n"),
+			}
+
+			Expect(provider.Reload(storage)).To(Succeed())
+
+			_, err := provider.Query("get-categories")
+			Expect(err).To(MatchError("query 'get-categories' not found"))
+
+			query, err := provider.Query("get-products")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal("SELECT * FROM products;"))
+		})
+
+		Context("when a routine is duplicated", func() {
+			It("keeps the loaded routines", func() {
+				storage["duplicate.sql"] = &fstest.MapFile{
+					Data: []byte("-- name: get-categories\nSELECT * FROM categories;\n"),
+				}
+
+				Expect(provider.Reload(storage)).To(MatchError("query 'get-categories' already exists"))
+
+				query, err := provider.Query("get-categories")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(query).To(Equal("SELECT * FROM categories;"))
+			})
+		})
+	})
+
+	Describe("Watch", func() {
+		var (
+			dir    string
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+
+		write := func(name, content string) {
+			Expect(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+
+			dir, err = ioutil.TempDir("", "prana_provider")
+			Expect(err).NotTo(HaveOccurred())
+
+			write("routine.sql", "-- name: get-categories\nSELECT * FROM categories;\n")
+			Expect(provider.ReadDir(os.DirFS(dir))).To(Succeed())
+
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+
+		AfterEach(func() {
+			cancel()
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("reloads the routines when the files change", func() {
+			errs := provider.Watch(ctx, os.DirFS(dir), 10*time.Millisecond)
+
+			write("routine.sql", "-- name: get-products\nSELECT * FROM products;\n")
+
+			Eventually(func() error {
+				_, err := provider.Query("get-products")
+				return err
+			}).Should(Succeed())
+
+			cancel()
+			Eventually(errs).Should(BeClosed())
+		})
+
+		Context("when a routine cannot be parsed", func() {
+			It("reports the error and keeps the loaded routines", func() {
+				errs := provider.Watch(ctx, os.DirFS(dir), 10*time.Millisecond)
+
+				write("routine.sql", "-- name: get-categories\n-- tx: maybe\nSELECT * FROM products;\n")
+
+				Eventually(errs).Should(Receive(MatchError("routine 'get-categories': invalid tx annotation 'maybe'")))
+
+				query, err := provider.Query("get-categories")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(query).To(Equal("SELECT * FROM categories;"))
+			})
+		})
+	})
 })
@@ -1,11 +1,12 @@
 package sqlexec
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"sync"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/olekukonko/tablewriter"
 )
 
 // Runner runs a SQL statement for given command name and parameters.
@@ -14,10 +15,60 @@ type Runner struct {
 	FileSystem FileSystem
 	// DB is a client to underlying database.
 	DB *sqlx.DB
+	// Provider provides the commands of a long-lived runner. If it's set,
+	// the FileSystem is not read on every call and the prepared statements
+	// are cached per command. The dialect of the provider should match the
+	// driver of the database.
+	Provider *Provider
+
+	mu    sync.Mutex
+	stmts map[string]*cachedStmt
 }
 
-// Run runs a given command with provided parameters.
-func (r *Runner) Run(name string, args ...Param) (*Rows, error) {
+// cachedStmt is a prepared statement of a command. The statement is closed
+// when it's retired and no longer used.
+type cachedStmt struct {
+	query   string
+	stmt    *sqlx.Stmt
+	refs    int
+	retired bool
+}
+
+// Query returns the query of a given command.
+func (r *Runner) Query(name string) (string, error) {
+	provider, err := r.provider()
+	if err != nil {
+		return "", err
+	}
+
+	return provider.Query(name)
+}
+
+// Routine returns the routine of a given command.
+func (r *Runner) Routine(name string) (*Routine, error) {
+	provider, err := r.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.Routine(name)
+}
+
+// Routines returns all routines sorted by name.
+func (r *Runner) Routines() ([]*Routine, error) {
+	provider, err := r.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.Routines(), nil
+}
+
+func (r *Runner) provider() (*Provider, error) {
+	if r.Provider != nil {
+		return r.Provider, nil
+	}
+
 	provider := &Provider{
 		dialect: r.DB.DriverName(),
 	}
@@ -26,11 +77,45 @@ func (r *Runner) Run(name string, args ...Param) (*Rows, error) {
 		return nil, err
 	}
 
-	query, err := provider.Query(name)
+	return provider, nil
+}
+
+// Run runs a given command with provided parameters.
+func (r *Runner) Run(name string, args ...Param) (*Rows, error) {
+	provider, err := r.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := r.bind(provider, name, args)
 	if err != nil {
 		return nil, err
 	}
 
+	if r.Provider != nil {
+		routine, err := provider.Routine(name)
+		if err != nil {
+			return nil, err
+		}
+
+		// the rendered templates differ by their parameters
+		if !IsTemplate(routine.Query) {
+			cached, err := r.prepare(routine.Name, query)
+			if err != nil {
+				return nil, err
+			}
+
+			// the rows keep the statement open until they are closed
+			rows, err := cached.stmt.Queryx(args...)
+
+			if relErr := r.release(cached); err == nil {
+				err = relErr
+			}
+
+			return rows, err
+		}
+	}
+
 	stmt, err := r.DB.Preparex(query)
 	if err != nil {
 		return nil, err
@@ -45,35 +130,311 @@ func (r *Runner) Run(name string, args ...Param) (*Rows, error) {
 	return stmt.Queryx(args...)
 }
 
-// Print prints the rows
-func (r *Runner) Print(writer io.Writer, rows *sqlx.Rows) error {
-	table := tablewriter.NewWriter(writer)
+// prepare returns the cached prepared statement of a given command. The
+// statement is prepared again if the query of the command has changed (e.g.
+// the provider has been reloaded). The returned statement must be released.
+// The replaced statement is closed once it's released by all callers.
+func (r *Runner) prepare(name, query string) (*cachedStmt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stmts == nil {
+		r.stmts = make(map[string]*cachedStmt)
+	}
+
+	cached, ok := r.stmts[name]
+
+	if ok && cached.query == query {
+		cached.refs++
+		return cached, nil
+	}
+
+	stmt, err := r.DB.Preparex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		if err := r.retire(cached); err != nil {
+			stmt.Close()
+			return nil, err
+		}
+	}
+
+	cached = &cachedStmt{query: query, stmt: stmt, refs: 1}
+	r.stmts[name] = cached
+	return cached, nil
+}
+
+// release releases the statement returned by prepare
+func (r *Runner) release(cached *cachedStmt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cached.refs--
+
+	if cached.retired && cached.refs == 0 {
+		return cached.stmt.Close()
+	}
+
+	return nil
+}
+
+// retire removes the statement from the cache. It's closed if it's not used.
+func (r *Runner) retire(cached *cachedStmt) error {
+	cached.retired = true
+
+	if cached.refs == 0 {
+		return cached.stmt.Close()
+	}
+
+	return nil
+}
+
+// Close closes the cached prepared statements. The statements that are in
+// use are closed once they are released. The runner can be used after it's
+// closed. The statements are prepared again when they are needed.
+func (r *Runner) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+
+	for name, cached := range r.stmts {
+		if stmtErr := r.retire(cached); err == nil {
+			err = stmtErr
+		}
+
+		delete(r.stmts, name)
+	}
+
+	return err
+}
+
+// Explain prints the execution plan of a given command with provided
+// parameters. The plan is analyzed by executing the command if the analyze
+// flag is set. The command is explained in a transaction that is rolled back
+// in any case.
+func (r *Runner) Explain(writer io.Writer, printer *Printer, name string, analyze bool, args ...Param) error {
+	provider, err := r.provider()
+	if err != nil {
+		return err
+	}
+
+	query, args, err := r.bind(provider, name, args)
+	if err != nil {
+		return err
+	}
 
-	columns, err := rows.Columns()
+	if query, err = Explain(r.DB.DriverName(), query, analyze); err != nil {
+		return fmt.Errorf("query '%s' cannot be explained: %v", name, err)
+	}
+
+	tx, err := r.DB.Beginx()
 	if err != nil {
 		return err
 	}
 
-	table.Header(columns)
+	rows, err := tx.Queryx(query, args...)
+	if err != nil {
+		return r.rollback(tx, err)
+	}
+
+	err = printer.Print(writer, rows)
+
+	if rowsErr := rows.Close(); err == nil {
+		err = rowsErr
+	}
+
+	if err != nil {
+		return r.rollback(tx, err)
+	}
+
+	return tx.Rollback()
+}
+
+// Exec executes a given command with provided parameters. The statements of
+// the command are split by the Splitter and executed in a single
+// transaction, unless the command is annotated with 'tx: none'. The
+// positional parameters are consumed by each statement according to its
+// number of placeholders.
+func (r *Runner) Exec(name string, args ...Param) ([]*Result, error) {
+	provider, err := r.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	routine, err := provider.Routine(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// the statements are rebound one by one as the numbered placeholders
+	// of each statement start from one
+	query := routine.Query
+	templated := IsTemplate(query)
 
-	for rows.Next() {
-		record, err := rows.SliceScan()
+	if templated {
+		param, err := r.param(name, args)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		if query, args, err = expand(provider.Dialect(), query, param); err != nil {
+			return nil, err
+		}
+	}
+
+	splitter := &Splitter{Dialect: provider.Dialect()}
+	statements := []string{}
+
+	for _, statement := range splitter.Split(bytes.NewBufferString(query)) {
+		if !isBlank(statement) {
+			statements = append(statements, statement)
+		}
+	}
+
+	// the arguments of the rendered template are positional
+	named := !templated && IsNamedParam(args...)
+
+	if named && len(ParamNames(query)) == 0 {
+		return nil, fmt.Errorf("query '%s' does not have named parameters", name)
+	}
+
+	if !named {
+		count := 0
+
+		for _, statement := range statements {
+			count += Placeholders(statement)
 		}
 
-		row := []string{}
+		if count != len(args) {
+			return nil, fmt.Errorf("query '%s' expects %d parameters but %d were given", name, count, len(args))
+		}
+	}
+
+	var (
+		tx *sqlx.Tx
+		db sqlx.Execer = r.DB
+	)
+
+	// the command annotated with 'tx: none' is executed outside of a
+	// transaction (e.g. CREATE INDEX CONCURRENTLY)
+	if routine.Tx != TxNone {
+		if tx, err = r.DB.Beginx(); err != nil {
+			return nil, err
+		}
+
+		db = tx
+	}
 
-		for _, column := range record {
-			if data, ok := column.([]byte); ok {
-				column = string(data)
+	results := []*Result{}
+
+	for _, statement := range statements {
+		var params []Param
+
+		if named {
+			if statement, params, err = BindNamed(statement, args[0]); err != nil {
+				return nil, r.rollback(tx, err)
 			}
-			row = append(row, fmt.Sprintf("%v", column))
+		} else {
+			count := Placeholders(statement)
+			params, args = args[:count], args[count:]
+		}
+
+		statement = r.DB.Rebind(statement)
+
+		result, err := db.Exec(statement, params...)
+		if err != nil {
+			return nil, r.rollback(tx, err)
 		}
 
-		table.Append(row)
+		item := &Result{Statement: statement}
+
+		// not all drivers support the rows affected and the last insert id
+		item.RowsAffected, _ = result.RowsAffected()
+		item.LastInsertID, _ = result.LastInsertId()
+
+		results = append(results, item)
 	}
 
-	table.Render()
-	return nil
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// bind returns the query of a given command with its arguments bound
+func (r *Runner) bind(provider *Provider, name string, args []Param) (string, []Param, error) {
+	query, err := provider.Query(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch {
+	case IsTemplate(query):
+		return r.render(provider, name, args)
+	case IsNamedParam(args...):
+		if len(ParamNames(query)) == 0 {
+			return "", nil, fmt.Errorf("query '%s' does not have named parameters", name)
+		}
+
+		if query, args, err = BindNamed(query, args[0]); err != nil {
+			return "", nil, err
+		}
+
+		return r.DB.Rebind(query), args, nil
+	default:
+		return query, args, nil
+	}
+}
+
+// render renders the templated command with the named parameters
+func (r *Runner) render(provider *Provider, name string, args []Param) (string, []Param, error) {
+	param, err := r.param(name, args)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return provider.Render(name, param)
+}
+
+// param returns the named parameters of a templated command
+func (r *Runner) param(name string, args []Param) (Param, error) {
+	switch {
+	case len(args) == 0:
+		return nil, nil
+	case IsNamedParam(args...):
+		return args[0], nil
+	default:
+		return nil, fmt.Errorf("query '%s' is a template that expects named parameters", name)
+	}
+}
+
+func (r *Runner) rollback(tx *sqlx.Tx, err error) error {
+	if tx == nil {
+		return err
+	}
+
+	if xerr := tx.Rollback(); xerr != nil {
+		return fmt.Errorf("%v: rollback failure: %v", err, xerr)
+	}
+
+	return err
+}
+
+// PrintResults prints the results of executed statements as table
+func (r *Runner) PrintResults(writer io.Writer, results []*Result) {
+	printer := &Printer{}
+	// the table format does not fail
+	_ = printer.PrintResults(writer, results)
+}
+
+// Print prints the rows as table
+func (r *Runner) Print(writer io.Writer, rows *sqlx.Rows) error {
+	printer := &Printer{}
+	return printer.Print(writer, rows)
 }
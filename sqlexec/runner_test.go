@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sync"
+	"testing"
 	"testing/fstest"
 
 	"github.com/jmoiron/sqlx"
@@ -63,6 +65,23 @@ var _ = Describe("Runner", func() {
 		Expect(columns).To(ContainElement("sql"))
 	})
 
+	Describe("Query", func() {
+		It("returns the query of the command", func() {
+			query, err := runner.Query("system-tables")
+			Expect(err).To(Succeed())
+			Expect(query).To(Equal("SELECT * FROM sqlite_master"))
+		})
+	})
+
+	Describe("Routines", func() {
+		It("returns all routines", func() {
+			routines, err := runner.Routines()
+			Expect(err).To(Succeed())
+			Expect(routines).To(HaveLen(1))
+			Expect(routines[0].Name).To(Equal("system-tables"))
+		})
+	})
+
 	Context("when the command requires parameters", func() {
 		JustBeforeEach(func() {
 			command := &bytes.Buffer{}
@@ -82,6 +101,388 @@ var _ = Describe("Runner", func() {
 			Expect(err).To(Succeed())
 			Expect(columns).To(ContainElement("Param"))
 		})
+
+		It("runs the command with a single time parameter", func() {
+			arg, err := sqlexec.ParseArg(":time=2020-01-01")
+			Expect(err).To(Succeed())
+
+			param, err := arg.Param()
+			Expect(err).To(Succeed())
+
+			rows, err := runner.Run("system-tables", param)
+			Expect(err).To(Succeed())
+			Expect(rows.Close()).To(Succeed())
+		})
+	})
+
+	Context("when the command has named parameters", func() {
+		JustBeforeEach(func() {
+			command := &bytes.Buffer{}
+			fmt.Fprintln(command, "-- name: system-tables")
+			fmt.Fprintln(command, "SELECT :name AS Name, ':skipped' AS Text")
+
+			storage["commands.sql"] = &fstest.MapFile{
+				Data: command.Bytes(),
+			}
+		})
+
+		It("runs the command successfully", func() {
+			rows, err := runner.Run("system-tables", map[string]interface{}{"name": "hello"})
+			Expect(err).To(Succeed())
+			Expect(rows.Next()).To(BeTrue())
+
+			record, err := rows.SliceScan()
+			Expect(err).To(Succeed())
+			Expect(record).To(Equal([]interface{}{"hello", ":skipped"}))
+			Expect(rows.Close()).To(Succeed())
+		})
+
+		Context("when a parameter is missing", func() {
+			It("returns an error", func() {
+				_, err := runner.Run("system-tables", map[string]interface{}{"id": "1"})
+				Expect(err).To(MatchError("could not find name name in map[string]interface {}{\"id\":\"1\"}"))
+			})
+		})
+	})
+
+	Context("when the command is a template", func() {
+		JustBeforeEach(func() {
+			command := &bytes.Buffer{}
+			fmt.Fprintln(command, "-- name: system-tables")
+			fmt.Fprintln(command, "SELECT name FROM sqlite_master WHERE 1 = 1{{if .names}} AND name IN {{in .names}}{{end}}")
+
+			storage["commands.sql"] = &fstest.MapFile{
+				Data: command.Bytes(),
+			}
+
+			_, err := runner.DB.Exec("CREATE TABLE users (id INT)")
+			Expect(err).To(Succeed())
+		})
+
+		It("runs the command successfully", func() {
+			rows, err := runner.Run("system-tables", map[string]interface{}{"names": []string{"users", "unknown"}})
+			Expect(err).To(Succeed())
+			Expect(rows.Next()).To(BeTrue())
+
+			record, err := rows.SliceScan()
+			Expect(err).To(Succeed())
+			Expect(record).To(Equal([]interface{}{"users"}))
+			Expect(rows.Next()).To(BeFalse())
+			Expect(rows.Close()).To(Succeed())
+		})
+
+		Context("when the parameters are positional", func() {
+			It("returns an error", func() {
+				_, err := runner.Run("system-tables", "users")
+				Expect(err).To(MatchError("query 'system-tables' is a template that expects named parameters"))
+			})
+		})
+	})
+
+	Context("when the command does not have named parameters", func() {
+		It("returns an error", func() {
+			_, err := runner.Run("system-tables", map[string]interface{}{"id": "1"})
+			Expect(err).To(MatchError("query 'system-tables' does not have named parameters"))
+		})
+	})
+
+	Describe("Exec", func() {
+		JustBeforeEach(func() {
+			command := &bytes.Buffer{}
+			fmt.Fprintln(command, "-- name: create-users")
+			fmt.Fprintln(command, "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT);")
+			fmt.Fprintln(command, "GO")
+			fmt.Fprintln(command, "INSERT INTO users (name) VALUES (?), (?);")
+			fmt.Fprintln(command, "GO")
+			fmt.Fprintln(command, "UPDATE users SET name = upper(name) WHERE name = ?;")
+			fmt.Fprintln(command)
+			fmt.Fprintln(command, "-- name: insert-user")
+			fmt.Fprintln(command, "INSERT INTO users (name) VALUES (:name);")
+			fmt.Fprintln(command)
+			fmt.Fprintln(command, "-- name: vacuum")
+			fmt.Fprintln(command, "-- tx: none")
+			fmt.Fprintln(command, "VACUUM;")
+
+			storage["users.sql"] = &fstest.MapFile{
+				Data: command.Bytes(),
+			}
+		})
+
+		It("executes the statements successfully", func() {
+			results, err := runner.Exec("create-users", "john", "jane", "jane")
+			Expect(err).To(Succeed())
+			Expect(results).To(HaveLen(3))
+
+			Expect(results[1].RowsAffected).To(BeEquivalentTo(2))
+			Expect(results[1].LastInsertID).To(BeEquivalentTo(2))
+			Expect(results[2].RowsAffected).To(BeEquivalentTo(1))
+
+			names := []string{}
+			Expect(runner.DB.Select(&names, "SELECT name FROM users ORDER BY id")).To(Succeed())
+			Expect(names).To(Equal([]string{"john", "JANE"}))
+		})
+
+		Context("when the command cannot be executed in a transaction", func() {
+			It("executes the statements without a transaction", func() {
+				results, err := runner.Exec("vacuum")
+				Expect(err).To(Succeed())
+				Expect(results).To(HaveLen(1))
+			})
+		})
+
+		It("prints the results", func() {
+			results, err := runner.Exec("create-users", "john", "jane", "jane")
+			Expect(err).To(Succeed())
+
+			w := &bytes.Buffer{}
+			runner.PrintResults(w, results)
+
+			Expect(w.String()).To(ContainSubstring("ROWS AFFECTED"))
+			Expect(w.String()).To(ContainSubstring("LAST INSERT ID"))
+		})
+
+		Context("when the command has named parameters", func() {
+			It("executes the statement successfully", func() {
+				_, err := runner.Exec("create-users", "john", "jane", "jane")
+				Expect(err).To(Succeed())
+
+				results, err := runner.Exec("insert-user", map[string]interface{}{"name": "peter"})
+				Expect(err).To(Succeed())
+				Expect(results).To(HaveLen(1))
+				Expect(results[0].LastInsertID).To(BeEquivalentTo(3))
+			})
+		})
+
+		Context("when the dialect has numbered placeholders", func() {
+			BeforeEach(func() {
+				// the placeholders are rebound for postgres, which sqlite
+				// accepts as numbered parameters
+				runner.DB = sqlx.NewDb(runner.DB.DB, "postgres")
+
+				_, err := runner.DB.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+				Expect(err).To(Succeed())
+			})
+
+			JustBeforeEach(func() {
+				command := &bytes.Buffer{}
+				fmt.Fprintln(command, "-- name: multi")
+				fmt.Fprintln(command, "INSERT INTO users (id, name) VALUES (?, ?);")
+				fmt.Fprintln(command, "INSERT INTO users (id, name) VALUES (?, 'peter');")
+
+				storage["users.sql"] = &fstest.MapFile{
+					Data: command.Bytes(),
+				}
+			})
+
+			It("numbers the placeholders of each statement", func() {
+				results, err := runner.Exec("multi", 1, "john", 2)
+				Expect(err).To(Succeed())
+				Expect(results).To(HaveLen(2))
+				Expect(results[0].Statement).To(ContainSubstring("VALUES ($1, $2)"))
+				Expect(results[1].Statement).To(ContainSubstring("VALUES ($1, 'peter')"))
+
+				names := []string{}
+				Expect(runner.DB.Select(&names, "SELECT name FROM users ORDER BY id")).To(Succeed())
+				Expect(names).To(Equal([]string{"john", "peter"}))
+			})
+		})
+
+		Context("when the parameters do not match the placeholders", func() {
+			It("returns an error", func() {
+				_, err := runner.Exec("create-users", "john")
+				Expect(err).To(MatchError("query 'create-users' expects 3 parameters but 1 were given"))
+			})
+		})
+
+		Context("when a statement fails", func() {
+			It("rollbacks the transaction", func() {
+				_, err := runner.Exec("create-users", "john", "jane", "jane")
+				Expect(err).To(Succeed())
+
+				_, err = runner.Exec("create-users", "john", "jane", "jane")
+				Expect(err).To(MatchError("table users already exists"))
+
+				count := 0
+				Expect(runner.DB.Get(&count, "SELECT count(*) FROM users")).To(Succeed())
+				Expect(count).To(Equal(2))
+			})
+		})
+	})
+
+	Describe("Explain", func() {
+		JustBeforeEach(func() {
+			command := &bytes.Buffer{}
+			fmt.Fprintln(command, "-- name: select-table")
+			fmt.Fprintln(command, "SELECT * FROM sqlite_master WHERE name = :name;")
+
+			storage["tables.sql"] = &fstest.MapFile{
+				Data: command.Bytes(),
+			}
+		})
+
+		It("prints the plan of the command", func() {
+			w := &bytes.Buffer{}
+			printer := &sqlexec.Printer{Format: sqlexec.FormatCSV}
+			param := map[string]interface{}{"name": "users"}
+
+			Expect(runner.Explain(w, printer, "select-table", false, param)).To(Succeed())
+			Expect(w.String()).To(HavePrefix("id,parent,notused,detail\n"))
+			Expect(w.String()).To(ContainSubstring("SCAN"))
+		})
+
+		Context("when the plan should be analyzed", func() {
+			It("returns an error", func() {
+				w := &bytes.Buffer{}
+				err := runner.Explain(w, &sqlexec.Printer{}, "system-tables", true)
+				Expect(err).To(MatchError("query 'system-tables' cannot be explained: dialect 'sqlite3' does not support EXPLAIN ANALYZE"))
+			})
+		})
+	})
+
+	Context("when the provider is set", func() {
+		var provider *sqlexec.Provider
+
+		JustBeforeEach(func() {
+			command := &bytes.Buffer{}
+			fmt.Fprintln(command, "-- name: select-name")
+			fmt.Fprintln(command, "SELECT ? AS name")
+			fmt.Fprintln(command)
+			fmt.Fprintln(command, "-- name: select-column")
+			fmt.Fprintln(command, "SELECT {{bind .value}} AS {{ident .column}}")
+
+			storage["names.sql"] = &fstest.MapFile{
+				Data: command.Bytes(),
+			}
+
+			provider = &sqlexec.Provider{}
+			provider.SetDialect("sqlite3")
+			Expect(provider.ReadDir(storage)).To(Succeed())
+
+			runner.Provider = provider
+			runner.FileSystem = fstest.MapFS{}
+		})
+
+		AfterEach(func() {
+			Expect(runner.Close()).To(Succeed())
+		})
+
+		name := func(args ...sqlexec.Param) string {
+			rows, err := runner.Run("select-name", args...)
+			Expect(err).To(Succeed())
+			defer rows.Close()
+
+			value := ""
+			Expect(rows.Next()).To(BeTrue())
+			Expect(rows.Scan(&value)).To(Succeed())
+			return value
+		}
+
+		It("runs the commands of the provider", func() {
+			Expect(name("jack")).To(Equal("jack"))
+			Expect(name("peter")).To(Equal("peter"))
+		})
+
+		It("prepares the statement again when the provider is reloaded", func() {
+			Expect(name("jack")).To(Equal("jack"))
+
+			storage["names.sql"] = &fstest.MapFile{
+				Data: []byte("-- name: select-name\nSELECT upper(?) AS name\n"),
+			}
+
+			Expect(provider.Reload(storage)).To(Succeed())
+			Expect(name("jack")).To(Equal("JACK"))
+		})
+
+		It("reads the rows of a replaced statement", func() {
+			rows, err := runner.Run("select-name", "jack")
+			Expect(err).To(Succeed())
+			defer rows.Close()
+
+			storage["names.sql"] = &fstest.MapFile{
+				Data: []byte("-- name: select-name\nSELECT upper(?) AS name\n"),
+			}
+
+			Expect(provider.Reload(storage)).To(Succeed())
+			Expect(name("jack")).To(Equal("JACK"))
+
+			value := ""
+			Expect(rows.Next()).To(BeTrue())
+			Expect(rows.Scan(&value)).To(Succeed())
+			Expect(value).To(Equal("jack"))
+		})
+
+		It("runs the commands while the provider is reloaded", func() {
+			upper := fstest.MapFS{
+				"names.sql": &fstest.MapFile{
+					Data: []byte("-- name: select-name\nSELECT upper(?) AS name\n"),
+				},
+			}
+
+			var (
+				group = sync.WaitGroup{}
+				done  = make(chan struct{})
+			)
+
+			group.Add(1)
+
+			go func() {
+				defer GinkgoRecover()
+				defer group.Done()
+
+				for index := 0; ; index++ {
+					select {
+					case <-done:
+						return
+					default:
+					}
+
+					if index%2 == 0 {
+						Expect(provider.Reload(upper)).To(Succeed())
+					} else {
+						Expect(provider.Reload(storage)).To(Succeed())
+					}
+
+					Expect(runner.Close()).To(Succeed())
+				}
+			}()
+
+			workers := sync.WaitGroup{}
+
+			for worker := 0; worker < 4; worker++ {
+				workers.Add(1)
+
+				go func() {
+					defer GinkgoRecover()
+					defer workers.Done()
+
+					for index := 0; index < 200; index++ {
+						Expect(name("jack")).To(Or(Equal("jack"), Equal("JACK")))
+					}
+				}()
+			}
+
+			workers.Wait()
+			close(done)
+			group.Wait()
+		})
+
+		It("prepares the statement again when the runner is closed", func() {
+			Expect(name("jack")).To(Equal("jack"))
+			Expect(runner.Close()).To(Succeed())
+			Expect(name("jack")).To(Equal("jack"))
+		})
+
+		It("runs the templated commands", func() {
+			param := map[string]interface{}{"value": "jack", "column": "name"}
+
+			rows, err := runner.Run("select-column", param)
+			Expect(err).To(Succeed())
+			defer rows.Close()
+
+			columns, err := rows.Columns()
+			Expect(err).To(Succeed())
+			Expect(columns).To(Equal([]string{"name"}))
+		})
 	})
 
 	Context("when the command does not exist", func() {
@@ -106,3 +507,61 @@ var _ = Describe("Runner", func() {
 		})
 	})
 })
+
+func BenchmarkRunnerRun(b *testing.B) {
+	benchmarkRunner(b, func(runner *sqlexec.Runner, storage fstest.MapFS) {})
+}
+
+func BenchmarkRunnerRunWithProvider(b *testing.B) {
+	benchmarkRunner(b, func(runner *sqlexec.Runner, storage fstest.MapFS) {
+		provider := &sqlexec.Provider{}
+		provider.SetDialect("sqlite3")
+
+		if err := provider.ReadDir(storage); err != nil {
+			b.Fatal(err)
+		}
+
+		runner.Provider = provider
+	})
+}
+
+func benchmarkRunner(b *testing.B, setup func(*sqlexec.Runner, fstest.MapFS)) {
+	storage := fstest.MapFS{}
+
+	for index := 0; index < 20; index++ {
+		command := &bytes.Buffer{}
+		fmt.Fprintf(command, "-- name: select-user-%d\n", index)
+		fmt.Fprintln(command, "SELECT name FROM sqlite_master WHERE name = ?")
+
+		storage[fmt.Sprintf("users_%d.sql", index)] = &fstest.MapFile{
+			Data: command.Bytes(),
+		}
+	}
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	runner := &sqlexec.Runner{
+		FileSystem: storage,
+		DB:         db,
+	}
+	defer runner.Close()
+
+	setup(runner, storage)
+
+	b.ResetTimer()
+
+	for index := 0; index < b.N; index++ {
+		rows, err := runner.Run("select-user-0", "users")
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if err := rows.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,12 +2,17 @@ package sqlexec
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"regexp"
 	"strings"
+	"time"
 )
 
-var nameRgxp = regexp.MustCompile("^\\s*--\\s*name:\\s*(\\S+)")
+var (
+	nameRgxp       = regexp.MustCompile("^\\s*--\\s*name:\\s*(\\S+)")
+	annotationRgxp = regexp.MustCompile("^\\s*--\\s*(doc|param|returns|timeout|tx|test):\\s*(.*?)\\s*$")
+)
 
 // Scanner loads a SQL statements for given SQL Script
 type Scanner struct{}
@@ -15,20 +20,90 @@ type Scanner struct{}
 // Scan scans a reader for SQL commands that have name tag
 func (s *Scanner) Scan(reader io.Reader) map[string]string {
 	queries := make(map[string]string)
-	scanner := bufio.NewScanner(reader)
-	name := ""
+
+	// the invalid annotations are reported by ScanRoutines
+	routines, _ := s.ScanRoutines(reader)
+
+	for name, routine := range routines {
+		queries[name] = routine.Query
+	}
+
+	return queries
+}
+
+// ScanRoutines scans a reader for SQL routines that have name tag. The
+// annotations that follow the name tag are parsed as routine metadata.
+func (s *Scanner) ScanRoutines(reader io.Reader) (map[string]*Routine, error) {
+	var (
+		routines = make(map[string]*Routine)
+		scanner  = bufio.NewScanner(reader)
+		routine  *Routine
+		header   bool
+		blank    []string
+		number   int
+		errs     = []string{}
+	)
 
 	for scanner.Scan() {
-		line := scanner.Text()
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		number++
 
 		if tag := s.tag(line); tag != "" {
-			name = tag
-		} else if name != "" {
-			s.add(name, queries, line)
+			routine = routines[tag]
+
+			if routine == nil {
+				routine = &Routine{Name: tag, StartLine: number}
+			}
+
+			header = true
+			blank = nil
+			continue
 		}
+
+		if routine == nil {
+			continue
+		}
+
+		if header {
+			if matches := annotationRgxp.FindStringSubmatch(line); matches != nil {
+				if err := s.annotate(routine, matches[1], matches[2]); err != nil {
+					errs = append(errs, fmt.Sprintf("routine '%s': %v", routine.Name, err))
+				}
+
+				continue
+			}
+
+			header = strings.TrimSpace(line) == ""
+		}
+
+		// the blank lines are kept only between the lines of the body
+		if strings.TrimSpace(line) == "" {
+			blank = append(blank, line)
+			continue
+		}
+
+		if routine.Query != "" {
+			blank = append(blank, line)
+			line = strings.Join(blank, "\n")
+		}
+
+		blank = nil
+
+		s.add(routine, routines, line)
+		routine.EndLine = number
 	}
 
-	return queries
+	// the routines are incomplete if the input is not read to the end (e.g.
+	// a line is too long)
+	if err := scanner.Err(); err != nil {
+		return routines, fmt.Errorf("line %d: %v", number+1, err)
+	}
+
+	if len(errs) > 0 {
+		return routines, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return routines, nil
 }
 
 func (s *Scanner) tag(line string) string {
@@ -39,18 +114,102 @@ func (s *Scanner) tag(line string) string {
 	return matches[1]
 }
 
-func (s *Scanner) add(name string, queries map[string]string, line string) {
-	current := queries[name]
-	line = strings.Trim(line, " \t")
+func (s *Scanner) annotate(routine *Routine, key, value string) error {
+	switch key {
+	case "doc":
+		if routine.Doc != "" {
+			routine.Doc = routine.Doc + "\n"
+		}
+
+		routine.Doc = routine.Doc + value
+	case "param":
+		fields := strings.Fields(value)
+
+		if len(fields) == 0 || len(fields) > 2 {
+			return fmt.Errorf("invalid param annotation '%s'", value)
+		}
+
+		param := &RoutineParam{Name: fields[0]}
+
+		if len(fields) == 2 {
+			param.Type = fields[1]
+		}
 
-	if len(line) == 0 {
-		return
+		routine.Params = append(routine.Params, param)
+	case "returns":
+		switch value {
+		case ReturnsOne, ReturnsMany, ReturnsExec:
+			routine.Returns = value
+		default:
+			return fmt.Errorf("invalid returns annotation '%s'", value)
+		}
+	case "timeout":
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid timeout annotation '%s'", value)
+		}
+
+		routine.Timeout = timeout
+	case "test":
+		test, err := s.test(value)
+		if err != nil {
+			return err
+		}
+
+		routine.Tests = append(routine.Tests, test)
+	case "tx":
+		switch value {
+		case TxRequired, TxNone:
+			routine.Tx = value
+		default:
+			return fmt.Errorf("invalid tx annotation '%s'", value)
+		}
 	}
 
-	if len(current) > 0 {
-		current = current + "\n"
+	return nil
+}
+
+// test parses a test annotation in the form of
+// params=[name][:type]=value,... expect=path
+func (s *Scanner) test(value string) (*RoutineTest, error) {
+	test := &RoutineTest{}
+
+	for _, field := range strings.Fields(value) {
+		parts := strings.SplitN(field, "=", 2)
+
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid test annotation '%s'", value)
+		}
+
+		switch parts[0] {
+		case "params":
+			for _, text := range strings.Split(parts[1], ",") {
+				arg, err := ParseArg(text)
+				if err != nil {
+					return nil, fmt.Errorf("invalid test annotation '%s': %v", value, err)
+				}
+
+				test.Params = append(test.Params, arg)
+			}
+		case "expect":
+			test.Expect = parts[1]
+		default:
+			return nil, fmt.Errorf("invalid test annotation '%s'", value)
+		}
+	}
+
+	if test.Expect == "" {
+		return nil, fmt.Errorf("test annotation '%s' does not have expect", value)
+	}
+
+	return test, nil
+}
+
+func (s *Scanner) add(routine *Routine, routines map[string]*Routine, line string) {
+	if len(routine.Query) > 0 {
+		routine.Query = routine.Query + "\n"
 	}
 
-	current = current + line
-	queries[name] = current
+	routine.Query = routine.Query + line
+	routines[routine.Name] = routine
 }
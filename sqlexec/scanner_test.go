@@ -3,6 +3,8 @@ package sqlexec_test
 import (
 	"bytes"
 	"fmt"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -54,4 +56,140 @@ var _ = Describe("Scanner", func() {
 			Expect(queries).To(HaveKeyWithValue("save-user", "SELECT * FROM users;"))
 		})
 	})
+
+	Describe("ScanRoutines", func() {
+		It("returns the routines with their annotations", func() {
+			buffer := &bytes.Buffer{}
+			fmt.Fprintln(buffer, "-- name: update-user")
+			fmt.Fprintln(buffer, "-- doc: Updates the user name.")
+			fmt.Fprintln(buffer, "-- doc: The user should exist.")
+			fmt.Fprintln(buffer, "-- param: id int")
+			fmt.Fprintln(buffer, "-- param: name")
+			fmt.Fprintln(buffer, "-- returns: exec")
+			fmt.Fprintln(buffer, "-- timeout: 5s")
+			fmt.Fprintln(buffer, "-- tx: required")
+			fmt.Fprintln(buffer, "-- the rest of the comments are part of the body")
+			fmt.Fprintln(buffer, "UPDATE users SET name = :name WHERE id = :id;")
+			fmt.Fprintln(buffer, "-- param: body")
+
+			routines, err := scanner.ScanRoutines(buffer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routines).To(HaveLen(1))
+
+			routine := routines["update-user"]
+			Expect(routine.Name).To(Equal("update-user"))
+			Expect(routine.Doc).To(Equal("Updates the user name.\nThe user should exist."))
+			Expect(routine.Params).To(Equal([]*sqlexec.RoutineParam{
+				{Name: "id", Type: "int"},
+				{Name: "name"},
+			}))
+			Expect(routine.Returns).To(Equal(sqlexec.ReturnsExec))
+			Expect(routine.Timeout).To(Equal(5 * time.Second))
+			Expect(routine.Tx).To(Equal(sqlexec.TxRequired))
+			Expect(routine.Query).To(Equal("-- the rest of the comments are part of the body\nUPDATE users SET name = :name WHERE id = :id;\n-- param: body"))
+		})
+
+		Context("when a line is too long", func() {
+			It("returns an error", func() {
+				buffer := &bytes.Buffer{}
+				fmt.Fprintln(buffer, "-- name: insert-document")
+				fmt.Fprintf(buffer, "INSERT INTO documents (body) VALUES ('%s');\n", strings.Repeat("a", 70000))
+
+				_, err := scanner.ScanRoutines(buffer)
+				Expect(err).To(MatchError("line 2: bufio.Scanner: token too long"))
+			})
+		})
+
+		It("keeps the body verbatim", func() {
+			buffer := &bytes.Buffer{}
+			fmt.Fprintln(buffer, "-- comment")
+			fmt.Fprintln(buffer, "-- name: create-function")
+			fmt.Fprintln(buffer)
+			fmt.Fprintln(buffer, "CREATE FUNCTION greet() RETURNS text AS $$")
+			fmt.Fprintln(buffer, "BEGIN")
+			fmt.Fprintln(buffer, "  RETURN 'Hello")
+			fmt.Fprintln(buffer)
+			fmt.Fprintln(buffer, "    World';")
+			fmt.Fprintln(buffer, "END;")
+			fmt.Fprintln(buffer, "$$ LANGUAGE plpgsql;")
+			fmt.Fprintln(buffer)
+			fmt.Fprintln(buffer, "-- name: select-users")
+			fmt.Fprintln(buffer, "SELECT * FROM users;\r")
+
+			routines, err := scanner.ScanRoutines(buffer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routines).To(HaveLen(2))
+
+			routine := routines["create-function"]
+			Expect(routine.Query).To(Equal("CREATE FUNCTION greet() RETURNS text AS $$\nBEGIN\n  RETURN 'Hello\n\n    World';\nEND;\n$$ LANGUAGE plpgsql;"))
+			Expect(routine.StartLine).To(Equal(2))
+			Expect(routine.EndLine).To(Equal(10))
+
+			routine = routines["select-users"]
+			Expect(routine.Query).To(Equal("SELECT * FROM users;"))
+			Expect(routine.StartLine).To(Equal(12))
+			Expect(routine.EndLine).To(Equal(13))
+		})
+
+		It("returns the test cases of the routines", func() {
+			buffer := &bytes.Buffer{}
+			fmt.Fprintln(buffer, "-- name: select-users")
+			fmt.Fprintln(buffer, "-- test: expect=golden/select-users.json")
+			fmt.Fprintln(buffer, "-- test: params=name=jack,age:int=42 expect=golden/select-jack.csv")
+			fmt.Fprintln(buffer, "SELECT * FROM users WHERE name = :name AND age = :age;")
+
+			routines, err := scanner.ScanRoutines(buffer)
+			Expect(err).NotTo(HaveOccurred())
+
+			routine := routines["select-users"]
+			Expect(routine.Tests).To(Equal([]*sqlexec.RoutineTest{
+				{Expect: "golden/select-users.json"},
+				{
+					Params: []*sqlexec.Arg{
+						{Name: "name", Value: "jack"},
+						{Name: "age", Type: "int", Value: "42"},
+					},
+					Expect: "golden/select-jack.csv",
+				},
+			}))
+		})
+
+		Context("when a test annotation is invalid", func() {
+			It("returns an error", func() {
+				buffer := &bytes.Buffer{}
+				fmt.Fprintln(buffer, "-- name: select-users")
+				fmt.Fprintln(buffer, "-- test: params=id:int=1")
+				fmt.Fprintln(buffer, "-- test: golden")
+				fmt.Fprintln(buffer, "SELECT * FROM users;")
+
+				_, err := scanner.ScanRoutines(buffer)
+				Expect(err).To(MatchError("routine 'select-users': test annotation 'params=id:int=1' does not have expect; routine 'select-users': invalid test annotation 'golden'"))
+			})
+		})
+
+		Context("when an annotation is invalid", func() {
+			It("returns an error", func() {
+				buffer := &bytes.Buffer{}
+				fmt.Fprintln(buffer, "-- name: save-user")
+				fmt.Fprintln(buffer, "-- timeout: soon")
+				fmt.Fprintln(buffer, "-- returns: everything")
+				fmt.Fprintln(buffer, "SELECT * FROM users;")
+
+				_, err := scanner.ScanRoutines(buffer)
+				Expect(err).To(MatchError("routine 'save-user': invalid timeout annotation 'soon'; routine 'save-user': invalid returns annotation 'everything'"))
+			})
+		})
+	})
+
+	Describe("Scan", func() {
+		It("returns the statements without annotations", func() {
+			buffer := &bytes.Buffer{}
+			fmt.Fprintln(buffer, "-- name: save-user")
+			fmt.Fprintln(buffer, "-- doc: Saves the user")
+			fmt.Fprintln(buffer, "SELECT * FROM users;")
+
+			queries := scanner.Scan(buffer)
+			Expect(queries).To(HaveKeyWithValue("save-user", "SELECT * FROM users;"))
+		})
+	})
 })
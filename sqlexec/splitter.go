@@ -1,45 +1,301 @@
 package sqlexec
 
 import (
-	"bufio"
-	"bytes"
-	"fmt"
 	"io"
+	"io/ioutil"
 	"regexp"
+	"strings"
 )
 
-var separatorRgxp = regexp.MustCompile(`^[\s]*[-]*[\s]*(?i)go[;]*\s*`)
+var (
+	separatorRgxp = regexp.MustCompile(`^\s*-*\s*(?i)go;*\s*(--.*)?$`)
+	delimiterRgxp = regexp.MustCompile(`^\s*(?i)delimiter\s+(\S+)\s*$`)
+	dollarRgxp    = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+	triggerRgxp   = regexp.MustCompile(`(?is)^CREATE\s+(?:TEMP\s+|TEMPORARY\s+)?TRIGGER\b.*\bBEGIN\b`)
+)
 
-// Splitter splits a statement by GO separator
-type Splitter struct{}
+// Splitter splits a script into statements. The statements are terminated by
+// semicolon or separated by GO line. The string literals, the quoted
+// identifiers, the comments and the PostgreSQL dollar-quoted strings are not
+// split. The MySQL DELIMITER command changes the terminator of the following
+// statements (e.g. for stored procedures). The SQLite triggers are
+// terminated by END followed by semicolon.
+type Splitter struct {
+	// Dialect is the SQL dialect of the script. The backslash escapes the
+	// quotes of the MySQL string literals (e.g. 'it\'s').
+	Dialect string
+}
 
-// Split splits a statement by GO separator
+// Split splits a script into statements
 func (s *Splitter) Split(reader io.Reader) []string {
-	buffer := &bytes.Buffer{}
-	queries := []string{}
-	scanner := bufio.NewScanner(reader)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if s.match(line) {
-			s.add(buffer, &queries)
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return []string{}
+	}
+
+	statements := []string{}
+
+	for _, segment := range newSplitter(s.Dialect, string(data)).split() {
+		statements = append(statements, segment.text)
+	}
+
+	return statements
+}
+
+// segment is a statement of the script with its start and end offset in the
+// input. The text does not contain the custom delimiter, so it's not always a
+// substring of the input.
+type segment struct {
+	text  string
+	start int
+	end   int
+}
+
+func newSplitter(dialect, text string) *splitter {
+	return &splitter{
+		input:     []rune(text),
+		delimiter: ";",
+		dialect:   dialect,
+	}
+}
+
+type splitter struct {
+	input     []rune
+	dialect   string
+	delimiter string
+	start     int
+	open      bool
+	segments  []segment
+}
+
+func (s *splitter) split() []segment {
+	input := s.input
+
+	for index := 0; index < len(input); index++ {
+		if index == 0 || input[index-1] == '\n' {
+			if next, ok := s.command(index); ok {
+				index = next
+				continue
+			}
+		}
+
+		char := input[index]
+
+		if s.delimiter != ";" && s.match(index, s.delimiter) {
+			index = s.terminate(index, index+len([]rune(s.delimiter)), false)
+			continue
+		}
+
+		switch {
+		case char == '\'' || char == '"' || char == '`':
+			index = s.quote(index, char)
+			s.open = index >= len(input)
+		case char == '-' && peek(input, index+1) == '-':
+			index = skipUntil(input, index, "\n")
+		case char == '/' && peek(input, index+1) == '*':
+			index = skipUntil(input, index+2, "*/")
+			s.open = index >= len(input)
+		case char == '$':
+			index = s.dollar(index)
+			s.open = index >= len(input)
+		case char == ';' && s.delimiter == ";" && !s.trigger(index):
+			index = s.terminate(index, index+1, true)
+		}
+	}
+
+	s.add(s.start, len(input))
+	return s.segments
+}
+
+// terminated returns true if the input does not end with an incomplete
+// statement (e.g. a missing terminator or an open string literal).
+func (s *splitter) terminated() bool {
+	return !s.open && s.delimiter == ";" && isBlank(string(s.input[s.start:]))
+}
+
+// command handles the GO separator and the DELIMITER command lines. It
+// returns the index of the line end.
+func (s *splitter) command(index int) (int, bool) {
+	end := index
+
+	for end < len(s.input) && s.input[end] != '\n' {
+		end++
+	}
+
+	line := string(s.input[index:end])
+
+	switch {
+	case separatorRgxp.MatchString(line):
+	case delimiterRgxp.MatchString(line):
+		s.delimiter = delimiterRgxp.FindStringSubmatch(line)[1]
+	default:
+		return index, false
+	}
+
+	s.add(s.start, index)
+	s.start = end + 1
+
+	return end, true
+}
+
+// terminate adds the statement that ends at given index. The rest of the line
+// is part of the statement if it contains only white spaces.
+func (s *splitter) terminate(index, next int, inclusive bool) int {
+	end := next
+
+	for end < len(s.input) && (s.input[end] == ' ' || s.input[end] == '\t' || s.input[end] == '\r') {
+		end++
+	}
+
+	if end < len(s.input) && s.input[end] == '\n' {
+		end++
+	} else {
+		end = next
+	}
+
+	statement := string(s.input[s.start:index])
+
+	if inclusive {
+		statement = string(s.input[s.start:next])
+	}
+
+	statement = statement + string(s.input[next:end])
+
+	if !isBlank(statement) {
+		s.segments = append(s.segments, segment{text: statement, start: s.start, end: end})
+	}
+
+	s.start = end
+	return end - 1
+}
+
+// quote skips the quoted string or identifier. The backslash escapes the next
+// character of the MySQL string literals.
+func (s *splitter) quote(index int, char rune) int {
+	if s.dialect != "mysql" || char == '`' {
+		return skipQuote(s.input, index, char)
+	}
+
+	for index++; index < len(s.input); index++ {
+		switch s.input[index] {
+		case '\\':
+			index++
+		case char:
+			// escaped quote
+			if peek(s.input, index+1) == char {
+				index++
+				continue
+			}
+
+			return index
+		}
+	}
+
+	return index
+}
+
+// skip skips the string literal, the quoted identifier, the comment or the
+// dollar-quoted string that starts at given index
+func (s *splitter) skip(index int) (int, bool) {
+	char := s.input[index]
+
+	switch {
+	case char == '\'' || char == '"' || char == '`':
+		return s.quote(index, char), true
+	case char == '-' && peek(s.input, index+1) == '-':
+		return skipUntil(s.input, index, "\n"), true
+	case char == '/' && peek(s.input, index+1) == '*':
+		return skipUntil(s.input, index+2, "*/"), true
+	case char == '$':
+		if next := s.dollar(index); next != index {
+			return next, true
+		}
+	}
+
+	return index, false
+}
+
+// dollar skips the dollar-quoted string (e.g. $$ ... $$ or $body$ ... $body$)
+func (s *splitter) dollar(index int) int {
+	tag := dollarRgxp.FindString(string(s.input[index:minInt(len(s.input), index+64)]))
+
+	if tag == "" {
+		return index
+	}
+
+	return skipUntil(s.input, index+len([]rune(tag)), tag)
+}
+
+// trigger returns true if the semicolon is part of the SQLite trigger body
+func (s *splitter) trigger(index int) bool {
+	statement := strip(string(s.input[s.start:index]))
+
+	if !triggerRgxp.MatchString(statement) {
+		return false
+	}
+
+	return s.depth(index) > 0
+}
+
+// depth returns the number of the BEGIN and CASE blocks that are not closed by
+// END between the start of the statement and given index
+func (s *splitter) depth(index int) int {
+	depth := 0
+
+	for position := s.start; position < index; position++ {
+		if next, ok := s.skip(position); ok {
+			position = next
 			continue
 		}
 
-		fmt.Fprintln(buffer, line)
+		char := s.input[position]
+
+		if !isParamStart(char) || (position > 0 && isWordPart(s.input[position-1])) {
+			continue
+		}
+
+		end := position
+
+		for end < index && isWordPart(s.input[end]) {
+			end++
+		}
+
+		switch strings.ToUpper(string(s.input[position:end])) {
+		case "BEGIN", "CASE":
+			depth++
+		case "END":
+			depth--
+		}
+
+		position = end - 1
 	}
 
-	s.add(buffer, &queries)
-	return queries
+	return depth
 }
 
-func (s *Splitter) match(line string) bool {
-	return separatorRgxp.MatchString(line)
+func (s *splitter) match(index int, value string) bool {
+	end := minInt(len(s.input), index+len([]rune(value)))
+	return string(s.input[index:end]) == value
 }
 
-func (s *Splitter) add(buffer *bytes.Buffer, queries *[]string) {
-	if buffer.Len() > 0 {
-		*queries = append(*queries, buffer.String())
-		buffer.Reset()
+func (s *splitter) add(start, end int) {
+	if start >= end {
+		return
 	}
+
+	if statement := string(s.input[start:end]); !isBlank(statement) {
+		s.segments = append(s.segments, segment{text: statement, start: start, end: end})
+	}
+}
+
+func isWordPart(char rune) bool {
+	return isParamStart(char) || (char >= '0' && char <= '9')
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
 }
@@ -89,6 +89,16 @@ var _ = Describe("Splitter", func() {
 			fmt.Fprintln(query, "SELECT * FROM documents;")
 		})
 
+		ItSplitsTheQuery()
+	})
+
+	Context("when the statement does not have a terminator", func() {
+		BeforeEach(func() {
+			query.Reset()
+			fmt.Fprintln(query, "SELECT *")
+			fmt.Fprintln(query, "FROM users")
+		})
+
 		It("does not split the query", func() {
 			stmt := query.String()
 			queries := splitter.Split(query)
@@ -96,4 +106,146 @@ var _ = Describe("Splitter", func() {
 			Expect(queries[0]).To(Equal(stmt))
 		})
 	})
+
+	Context("when the statements are on the same line", func() {
+		It("splits the query", func() {
+			queries := splitter.Split(bytes.NewBufferString("SELECT 1; SELECT 2;"))
+			Expect(queries).To(Equal([]string{"SELECT 1;", " SELECT 2;"}))
+		})
+	})
+
+	Context("when the semicolon is quoted", func() {
+		BeforeEach(func() {
+			query.Reset()
+			fmt.Fprintln(query, "INSERT INTO users (name, \"na;me\") VALUES ('John;', 'it''s;');")
+			fmt.Fprintln(query, "-- comment;")
+			fmt.Fprintln(query, "/* block; */ SELECT * FROM users;")
+		})
+
+		It("does not split the literals and the comments", func() {
+			queries := splitter.Split(query)
+			Expect(queries).To(HaveLen(2))
+			Expect(queries[0]).To(Equal("INSERT INTO users (name, \"na;me\") VALUES ('John;', 'it''s;');\n"))
+			Expect(queries[1]).To(Equal("-- comment;\n/* block; */ SELECT * FROM users;\n"))
+		})
+	})
+
+	Context("when the quote is escaped by backslash", func() {
+		BeforeEach(func() {
+			query.Reset()
+			fmt.Fprintln(query, `INSERT INTO notes (body) VALUES ('it\'s; here', "say \"hi;\"", 'C:\\');`)
+			fmt.Fprintln(query, "SELECT * FROM notes;")
+		})
+
+		Context("when the dialect is mysql", func() {
+			BeforeEach(func() {
+				splitter.Dialect = "mysql"
+			})
+
+			It("does not split the literals", func() {
+				queries := splitter.Split(query)
+				Expect(queries).To(HaveLen(2))
+				Expect(queries[0]).To(Equal(`INSERT INTO notes (body) VALUES ('it\'s; here', "say \"hi;\"", 'C:\\');` + "\n"))
+				Expect(queries[1]).To(Equal("SELECT * FROM notes;\n"))
+			})
+		})
+
+		Context("when the dialect is postgres", func() {
+			BeforeEach(func() {
+				splitter.Dialect = "postgres"
+			})
+
+			It("treats the backslash as a character", func() {
+				queries := splitter.Split(bytes.NewBufferString(`SELECT 'C:\'; SELECT 1;`))
+				Expect(queries).To(Equal([]string{`SELECT 'C:\';`, " SELECT 1;"}))
+			})
+		})
+	})
+
+	Context("when the script has comments only", func() {
+		It("skips them", func() {
+			queries := splitter.Split(bytes.NewBufferString("SELECT 1;\n-- the end\n"))
+			Expect(queries).To(Equal([]string{"SELECT 1;\n"}))
+		})
+	})
+
+	Context("when the body is dollar quoted", func() {
+		BeforeEach(func() {
+			query.Reset()
+			fmt.Fprintln(query, "CREATE FUNCTION one() RETURNS integer AS $$")
+			fmt.Fprintln(query, "BEGIN")
+			fmt.Fprintln(query, "  RETURN 1;")
+			fmt.Fprintln(query, "END;")
+			fmt.Fprintln(query, "$$ LANGUAGE plpgsql;")
+			fmt.Fprintln(query, "CREATE FUNCTION two() RETURNS integer AS $body$ SELECT 2; $body$ LANGUAGE sql;")
+			fmt.Fprintln(query, "SELECT $1;")
+		})
+
+		It("does not split the body", func() {
+			queries := splitter.Split(query)
+			Expect(queries).To(HaveLen(3))
+			Expect(queries[0]).To(HavePrefix("CREATE FUNCTION one()"))
+			Expect(queries[0]).To(HaveSuffix("$$ LANGUAGE plpgsql;\n"))
+			Expect(queries[1]).To(Equal("CREATE FUNCTION two() RETURNS integer AS $body$ SELECT 2; $body$ LANGUAGE sql;\n"))
+			Expect(queries[2]).To(Equal("SELECT $1;\n"))
+		})
+	})
+
+	Context("when the delimiter is changed", func() {
+		BeforeEach(func() {
+			query.Reset()
+			fmt.Fprintln(query, "DELIMITER //")
+			fmt.Fprintln(query, "CREATE PROCEDURE one()")
+			fmt.Fprintln(query, "BEGIN")
+			fmt.Fprintln(query, "  SELECT 1;")
+			fmt.Fprintln(query, "END //")
+			fmt.Fprintln(query, "DELIMITER ;")
+			fmt.Fprintln(query, "CALL one();")
+		})
+
+		It("splits the query by the delimiter", func() {
+			queries := splitter.Split(query)
+			Expect(queries).To(HaveLen(2))
+			Expect(queries[0]).To(Equal("CREATE PROCEDURE one()\nBEGIN\n  SELECT 1;\nEND \n"))
+			Expect(queries[1]).To(Equal("CALL one();\n"))
+		})
+	})
+
+	Context("when the script has a trigger", func() {
+		BeforeEach(func() {
+			query.Reset()
+			fmt.Fprintln(query, "CREATE TRIGGER touch AFTER UPDATE ON users")
+			fmt.Fprintln(query, "BEGIN")
+			fmt.Fprintln(query, "  UPDATE users SET updated_at = 1 WHERE id = NEW.id;")
+			fmt.Fprintln(query, "END;")
+			fmt.Fprintln(query, "SELECT * FROM users;")
+		})
+
+		It("does not split the trigger body", func() {
+			queries := splitter.Split(query)
+			Expect(queries).To(HaveLen(2))
+			Expect(queries[0]).To(HaveSuffix("END;\n"))
+			Expect(queries[1]).To(Equal("SELECT * FROM users;\n"))
+		})
+	})
+
+	Context("when the trigger body has a CASE expression", func() {
+		BeforeEach(func() {
+			query.Reset()
+			fmt.Fprintln(query, "CREATE TRIGGER touch AFTER UPDATE ON users")
+			fmt.Fprintln(query, "BEGIN")
+			fmt.Fprintln(query, "  UPDATE users SET role = CASE WHEN NEW.admin THEN 'admin' ELSE 'user' END;")
+			fmt.Fprintln(query, "  UPDATE users SET updated_at = 1 WHERE id = NEW.id;")
+			fmt.Fprintln(query, "END;")
+			fmt.Fprintln(query, "SELECT 'end;' FROM users;")
+		})
+
+		It("does not split the trigger body", func() {
+			queries := splitter.Split(query)
+			Expect(queries).To(HaveLen(2))
+			Expect(queries[0]).To(HavePrefix("CREATE TRIGGER touch"))
+			Expect(queries[0]).To(HaveSuffix("WHERE id = NEW.id;\nEND;\n"))
+			Expect(queries[1]).To(Equal("SELECT 'end;' FROM users;\n"))
+		})
+	})
 })
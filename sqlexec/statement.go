@@ -0,0 +1,145 @@
+package sqlexec
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	queryRgxp     = regexp.MustCompile(`(?i)^(SELECT|WITH|SHOW|PRAGMA|EXPLAIN|VALUES|DESCRIBE|DESC|TABLE)\b`)
+	returningRgxp = regexp.MustCompile(`(?i)\bRETURNING\b`)
+	withRgxp      = regexp.MustCompile(`(?i)^WITH\b`)
+	modifyRgxp    = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE)\b`)
+	schemaRgxp    = regexp.MustCompile(`(?i)^(CREATE|ALTER|DROP|RENAME)\b`)
+)
+
+// Result represents the result of executed statement.
+type Result struct {
+	// Statement is the executed statement.
+	Statement string
+	// RowsAffected is the number of rows affected by the statement.
+	RowsAffected int64
+	// LastInsertID is the last inserted id if the database supports it.
+	LastInsertID int64
+}
+
+// IsQuery returns true if the query is a single statement that returns rows
+// (e.g. SELECT or a statement with RETURNING clause).
+func IsQuery(query string) bool {
+	splitter := &Splitter{}
+	statements := splitter.Split(bytes.NewBufferString(query))
+
+	if len(statements) != 1 {
+		return false
+	}
+
+	statement := strip(statements[0])
+	return queryRgxp.MatchString(statement) || returningRgxp.MatchString(statement)
+}
+
+// IsReadOnly returns true if the query is a single statement that returns
+// rows without modifying the data (e.g. SELECT without data-modifying common
+// table expressions).
+func IsReadOnly(query string) bool {
+	if !IsQuery(query) {
+		return false
+	}
+
+	statement := strip(query)
+
+	if returningRgxp.MatchString(statement) {
+		return false
+	}
+
+	return !withRgxp.MatchString(statement) || !modifyRgxp.MatchString(statement)
+}
+
+// ReturnsRows returns true if the routine returns rows. The returns
+// annotation takes precedence over the statement of the routine.
+func ReturnsRows(routine *Routine) bool {
+	switch routine.Returns {
+	case ReturnsExec:
+		return false
+	case ReturnsOne, ReturnsMany:
+		return true
+	default:
+		return IsQuery(routine.Query)
+	}
+}
+
+// strip removes the leading comments and white spaces of the statement
+func strip(statement string) string {
+	input := []rune(statement)
+
+	for index := 0; index < len(input); index++ {
+		char := input[index]
+
+		switch {
+		case char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == '(':
+			continue
+		case char == '-' && peek(input, index+1) == '-':
+			index = skipUntil(input, index, "\n")
+		case char == '/' && peek(input, index+1) == '*':
+			index = skipUntil(input, index+2, "*/")
+		default:
+			return string(input[index:])
+		}
+	}
+
+	return ""
+}
+
+// Placeholders returns the number of positional placeholders (e.g. ? or $1)
+// in the statement. The string literals, the comments and the PostgreSQL
+// dollar-quoted strings are skipped. The question marks of the PostgreSQL
+// JSON operators (e.g. ?| and ?&) are not placeholders, neither are the
+// question marks of a statement that has numbered placeholders.
+func Placeholders(statement string) int {
+	var (
+		lexer = newSplitter("", statement)
+		input = lexer.input
+		count = 0
+		max   = 0
+	)
+
+	for index := 0; index < len(input); index++ {
+		if next, ok := lexer.skip(index); ok {
+			index = next
+			continue
+		}
+
+		char := input[index]
+
+		switch {
+		case char == '?' && (peek(input, index+1) == '&' || (peek(input, index+1) == '|' && peek(input, index+2) != '|')):
+			index++
+		case char == '?':
+			count++
+		case char == '$':
+			start := index + 1
+			end := start
+
+			for end < len(input) && input[end] >= '0' && input[end] <= '9' {
+				end++
+			}
+
+			if position, err := strconv.Atoi(string(input[start:end])); err == nil && position > max {
+				max = position
+			}
+
+			index = end - 1
+		}
+	}
+
+	if max > 0 {
+		return max
+	}
+
+	return count
+}
+
+func isBlank(statement string) bool {
+	return strings.TrimSpace(strip(statement)) == ""
+}
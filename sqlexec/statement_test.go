@@ -0,0 +1,68 @@
+package sqlexec_test
+
+import (
+	"github.com/phogolabs/prana/sqlexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IsQuery", func() {
+	It("returns true for statements that return rows", func() {
+		Expect(sqlexec.IsQuery("SELECT * FROM users")).To(BeTrue())
+		Expect(sqlexec.IsQuery("-- comment\n/* block */ with t AS (SELECT 1) SELECT * FROM t")).To(BeTrue())
+		Expect(sqlexec.IsQuery("PRAGMA table_info(users)")).To(BeTrue())
+		Expect(sqlexec.IsQuery("(SELECT 1) UNION (SELECT 2)")).To(BeTrue())
+		Expect(sqlexec.IsQuery("INSERT INTO users (name) VALUES ('John') RETURNING id")).To(BeTrue())
+	})
+
+	It("returns false for statements that do not return rows", func() {
+		Expect(sqlexec.IsQuery("UPDATE users SET name = 'John'")).To(BeFalse())
+		Expect(sqlexec.IsQuery("DELETE FROM users")).To(BeFalse())
+		Expect(sqlexec.IsQuery("CREATE TABLE selected (id INT)")).To(BeFalse())
+	})
+
+	Context("when the query has multiple statements", func() {
+		It("returns false", func() {
+			Expect(sqlexec.IsQuery("SELECT 1;\nGO\nSELECT 2;")).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("IsReadOnly", func() {
+	It("returns true for queries without side effects", func() {
+		Expect(sqlexec.IsReadOnly("SELECT * FROM users")).To(BeTrue())
+		Expect(sqlexec.IsReadOnly("WITH t AS (SELECT 1) SELECT * FROM t")).To(BeTrue())
+		Expect(sqlexec.IsReadOnly("SELECT * FROM users FOR UPDATE")).To(BeTrue())
+	})
+
+	It("returns false for statements with side effects", func() {
+		Expect(sqlexec.IsReadOnly("INSERT INTO users (name) VALUES ('John') RETURNING id")).To(BeFalse())
+		Expect(sqlexec.IsReadOnly("WITH t AS (DELETE FROM users) SELECT 1")).To(BeFalse())
+		Expect(sqlexec.IsReadOnly("UPDATE users SET name = 'John'")).To(BeFalse())
+	})
+})
+
+var _ = Describe("Placeholders", func() {
+	It("returns the number of question marks", func() {
+		Expect(sqlexec.Placeholders("SELECT * FROM users WHERE id = ? AND name = '?' -- ?")).To(Equal(1))
+	})
+
+	It("returns the highest numbered placeholder", func() {
+		Expect(sqlexec.Placeholders("SELECT * FROM users WHERE id = $2 OR parent_id = $1 OR owner_id = $2")).To(Equal(2))
+	})
+
+	It("skips the dollar-quoted strings", func() {
+		Expect(sqlexec.Placeholders("SELECT $$is it?$$, $tag$why? $1$tag$ WHERE id = ?")).To(Equal(1))
+	})
+
+	It("skips the JSON operators", func() {
+		Expect(sqlexec.Placeholders("SELECT * FROM docs WHERE data ?| array['a'] AND data ?& array['b'] AND name = ? || ?")).To(Equal(2))
+	})
+
+	Context("when the statement has numbered placeholders", func() {
+		It("does not count the question marks", func() {
+			Expect(sqlexec.Placeholders("SELECT * FROM docs WHERE data ? 'key' AND id = $1")).To(Equal(1))
+		})
+	})
+})
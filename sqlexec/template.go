@@ -0,0 +1,205 @@
+package sqlexec
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// IsTemplate returns true if the query is a Golang template that should be
+// rendered with the parameters before its execution. The query is a template
+// if it has actions outside of the string literals, the quoted identifiers and
+// the comments, so the braces of a JSON literal (e.g. '{"a": {"b": 1}}') or a
+// PostgreSQL array literal (e.g. '{{1,2},{3,4}}') do not make it a template.
+func IsTemplate(query string) bool {
+	if !strings.Contains(query, "{{") {
+		return false
+	}
+
+	lexer := newSplitter("", query)
+	input := lexer.input
+
+	for index := 0; index < len(input); index++ {
+		if next, ok := lexer.skip(index); ok {
+			index = next
+			continue
+		}
+
+		if input[index] == '{' && peek(input, index+1) == '{' {
+			return hasActions(query)
+		}
+	}
+
+	return false
+}
+
+// hasActions returns true if the parsed template has other nodes than text.
+// The template that cannot be parsed is reported when it's rendered.
+func hasActions(query string) bool {
+	renderer := &renderer{}
+
+	tmpl, err := template.New("query").
+		Funcs(renderer.funcs()).
+		Parse(query)
+
+	if err != nil || tmpl.Tree == nil {
+		return true
+	}
+
+	for _, node := range tmpl.Tree.Root.Nodes {
+		if node.Type() != parse.NodeText {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Render renders a templated query with given parameter. The values are bound
+// with the template functions and returned as positional arguments in the
+// order of their placeholders. The supported functions are:
+//
+//	bind      binds a value (e.g. name = {{bind .name}})
+//	in        binds a list of values (e.g. id IN {{in .ids}})
+//	ident     quotes an identifier (e.g. {{ident .column}})
+//	orderBy   quotes an identifier if it's allowed (e.g. {{orderBy .sort "id" "name"}})
+//	direction returns ASC or DESC (e.g. {{direction .order}})
+//
+// The identifiers are quoted for given dialect and the placeholders are
+// rebound for it.
+func Render(dialect, query string, param Param) (string, []Param, error) {
+	query, args, err := expand(dialect, query, param)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sqlx.Rebind(sqlx.BindType(dialect), query), args, nil
+}
+
+// expand renders the template with question mark placeholders
+func expand(dialect, query string, param Param) (string, []Param, error) {
+	renderer := &renderer{dialect: dialect}
+
+	tmpl, err := template.New("query").
+		Option("missingkey=zero").
+		Funcs(renderer.funcs()).
+		Parse(query)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if param == nil {
+		param = map[string]interface{}{}
+	}
+
+	buffer := &bytes.Buffer{}
+
+	if err := tmpl.Execute(buffer, param); err != nil {
+		return "", nil, err
+	}
+
+	return buffer.String(), renderer.args, nil
+}
+
+type renderer struct {
+	dialect string
+	args    []Param
+}
+
+func (r *renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"bind":      r.bind,
+		"in":        r.in,
+		"ident":     r.ident,
+		"orderBy":   r.orderBy,
+		"direction": r.direction,
+	}
+}
+
+func (r *renderer) bind(value interface{}) string {
+	r.args = append(r.args, value)
+	return "?"
+}
+
+func (r *renderer) in(value interface{}) (string, error) {
+	list := reflect.ValueOf(value)
+
+	switch list.Kind() {
+	case reflect.Slice, reflect.Array:
+	default:
+		return "", fmt.Errorf("in: expected a list but got %T", value)
+	}
+
+	if list.Len() == 0 {
+		// an empty list does not match any value
+		return "(NULL)", nil
+	}
+
+	placeholders := make([]string, list.Len())
+
+	for index := range placeholders {
+		placeholders[index] = r.bind(list.Index(index).Interface())
+	}
+
+	return "(" + strings.Join(placeholders, ", ") + ")", nil
+}
+
+func (r *renderer) ident(value interface{}) (string, error) {
+	name := text(value)
+
+	if name == "" {
+		return "", fmt.Errorf("ident: empty identifier")
+	}
+
+	quote := `"`
+
+	if r.dialect == "mysql" {
+		quote = "`"
+	}
+
+	parts := strings.Split(name, ".")
+
+	for index, part := range parts {
+		parts[index] = quote + strings.Replace(part, quote, quote+quote, -1) + quote
+	}
+
+	return strings.Join(parts, "."), nil
+}
+
+func (r *renderer) orderBy(value interface{}, allowed ...string) (string, error) {
+	column := text(value)
+
+	for _, name := range allowed {
+		if strings.EqualFold(name, column) {
+			return r.ident(name)
+		}
+	}
+
+	return "", fmt.Errorf("orderBy: column '%s' is not allowed", column)
+}
+
+func (r *renderer) direction(value interface{}) (string, error) {
+	switch strings.ToUpper(text(value)) {
+	case "", "ASC":
+		return "ASC", nil
+	case "DESC":
+		return "DESC", nil
+	default:
+		return "", fmt.Errorf("direction: invalid direction '%v'", value)
+	}
+}
+
+// text returns the value as string. The missing values are empty.
+func text(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,137 @@
+package sqlexec_test
+
+import (
+	"github.com/phogolabs/prana/sqlexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Render", func() {
+	query := "SELECT * FROM users WHERE 1 = 1" +
+		"{{if .name}} AND name = {{bind .name}}{{end}}" +
+		"{{if .ids}} AND id IN {{in .ids}}{{end}}" +
+		" ORDER BY {{orderBy .sort \"id\" \"created_at\"}} {{direction .order}}"
+
+	It("renders the query with the parameters", func() {
+		param := map[string]interface{}{
+			"name":  "John",
+			"ids":   []int{1, 2},
+			"sort":  "created_at",
+			"order": "desc",
+		}
+
+		query, args, err := sqlexec.Render("postgres", query, param)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal(`SELECT * FROM users WHERE 1 = 1 AND name = $1 AND id IN ($2, $3) ORDER BY "created_at" DESC`))
+		Expect(args).To(Equal([]interface{}{"John", 1, 2}))
+	})
+
+	It("omits the optional fragments", func() {
+		param := map[string]interface{}{
+			"sort": "id",
+		}
+
+		query, args, err := sqlexec.Render("sqlite3", query, param)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal(`SELECT * FROM users WHERE 1 = 1 ORDER BY "id" ASC`))
+		Expect(args).To(BeEmpty())
+	})
+
+	It("renders the query with a struct", func() {
+		param := struct {
+			IDs []string
+		}{
+			IDs: []string{"a", "b"},
+		}
+
+		query, args, err := sqlexec.Render("mysql", "SELECT * FROM users WHERE id IN {{in .IDs}}", param)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(query).To(Equal("SELECT * FROM users WHERE id IN (?, ?)"))
+		Expect(args).To(Equal([]interface{}{"a", "b"}))
+	})
+
+	Context("when the list is empty", func() {
+		It("does not match any value", func() {
+			param := map[string]interface{}{
+				"ids": []int{},
+			}
+
+			query, args, err := sqlexec.Render("sqlite3", "SELECT * FROM users WHERE id IN {{in .ids}}", param)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal("SELECT * FROM users WHERE id IN (NULL)"))
+			Expect(args).To(BeEmpty())
+		})
+	})
+
+	Context("when the identifier is quoted", func() {
+		It("escapes the quotes for the dialect", func() {
+			param := map[string]interface{}{
+				"table": "public.us\"ers",
+			}
+
+			query, _, err := sqlexec.Render("postgres", "SELECT * FROM {{ident .table}}", param)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal(`SELECT * FROM "public"."us""ers"`))
+
+			param["table"] = "us`ers"
+
+			query, _, err = sqlexec.Render("mysql", "SELECT * FROM {{ident .table}}", param)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal("SELECT * FROM `us``ers`"))
+		})
+	})
+
+	Context("when the order by column is not allowed", func() {
+		It("returns an error", func() {
+			param := map[string]interface{}{
+				"sort": "password; DROP TABLE users",
+			}
+
+			_, _, err := sqlexec.Render("sqlite3", query, param)
+			Expect(err).To(MatchError(ContainSubstring("orderBy: column 'password; DROP TABLE users' is not allowed")))
+		})
+	})
+
+	Context("when the direction is invalid", func() {
+		It("returns an error", func() {
+			param := map[string]interface{}{
+				"sort":  "id",
+				"order": "sideways",
+			}
+
+			_, _, err := sqlexec.Render("sqlite3", query, param)
+			Expect(err).To(MatchError(ContainSubstring("direction: invalid direction 'sideways'")))
+		})
+	})
+
+	Context("when the template is invalid", func() {
+		It("returns an error", func() {
+			_, _, err := sqlexec.Render("sqlite3", "SELECT {{if .name}}", nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("IsTemplate", func() {
+	It("returns true for the query with actions", func() {
+		Expect(sqlexec.IsTemplate("SELECT * FROM users WHERE id = {{bind .id}}")).To(BeTrue())
+		Expect(sqlexec.IsTemplate("SELECT '{}' AS doc FROM users {{if .id}}WHERE id = {{bind .id}}{{end}}")).To(BeTrue())
+	})
+
+	It("returns true for the malformed template", func() {
+		Expect(sqlexec.IsTemplate("SELECT * FROM users WHERE id = {{bind .id")).To(BeTrue())
+	})
+
+	It("returns false for the query without actions", func() {
+		Expect(sqlexec.IsTemplate("SELECT * FROM users")).To(BeFalse())
+	})
+
+	It("returns false for the braces in the literals and the comments", func() {
+		Expect(sqlexec.IsTemplate(`SELECT '{"user": {"id": 1}}'::jsonb`)).To(BeFalse())
+		Expect(sqlexec.IsTemplate("SELECT '{{1,2},{3,4}}'::int[]")).To(BeFalse())
+		Expect(sqlexec.IsTemplate("SELECT $${{ .id }}$$")).To(BeFalse())
+		Expect(sqlexec.IsTemplate("SELECT \"{{id}}\" FROM users -- {{bind .id}}")).To(BeFalse())
+		Expect(sqlexec.IsTemplate("SELECT 1 /* {{bind .id}} */")).To(BeFalse())
+	})
+})
@@ -0,0 +1,273 @@
+package sqlexec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Tester runs the declared test cases of the routines and compares their
+// results with the golden files. Each test case runs in a transaction that is
+// seeded with the fixtures and rolled back. The test cases are declared with
+// test annotation:
+//
+//	-- name: select-user
+//	-- test: params=id:int=1 expect=golden/select-user.json
+//	SELECT * FROM users WHERE id = :id
+//
+// The format of the golden file is determined by its extension (json, ndjson,
+// csv, tsv, yaml, md). The result of a routine that does not return rows is
+// the number of affected rows as JSON.
+type Tester struct {
+	// FileSystem represents the routine directory file system.
+	FileSystem WriteFileSystem
+	// Fixtures contains the SQL scripts that seed the database. They are
+	// executed in the order of their names.
+	Fixtures FileSystem
+	// DB is a client to underlying database.
+	DB *sqlx.DB
+	// Update rewrites the golden files with the actual results.
+	Update bool
+}
+
+// TestResult represents the result of a test case.
+type TestResult struct {
+	// Routine is the tested routine
+	Routine *Routine
+	// Test is the test case
+	Test *RoutineTest
+	// Path is the path of the golden file
+	Path string
+	// Expected is the content of the golden file
+	Expected []byte
+	// Actual is the result of the routine
+	Actual []byte
+	// Updated is true if the golden file was rewritten
+	Updated bool
+	// Err is the failure of the test case
+	Err error
+}
+
+// Test runs the test cases of the routines with given names. It runs the
+// test cases of all routines if the names are not provided.
+func (t *Tester) Test(names ...string) ([]*TestResult, error) {
+	provider := &Provider{
+		dialect: t.DB.DriverName(),
+	}
+
+	if err := provider.ReadDir(t.FileSystem); err != nil {
+		return nil, err
+	}
+
+	routines := provider.Routines()
+
+	if len(names) > 0 {
+		routines = []*Routine{}
+
+		for _, name := range names {
+			routine, err := provider.Routine(name)
+			if err != nil {
+				return nil, err
+			}
+
+			routines = append(routines, routine)
+		}
+	}
+
+	fixtures, err := t.fixtures()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*TestResult{}
+
+	for _, routine := range routines {
+		for _, test := range routine.Tests {
+			result := &TestResult{
+				Routine: routine,
+				Test:    test,
+				Path:    path.Join(path.Dir(routine.File), test.Expect),
+			}
+
+			t.run(provider, fixtures, result)
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+func (t *Tester) run(provider *Provider, fixtures []string, result *TestResult) {
+	result.Actual, result.Err = t.execute(provider, fixtures, result)
+
+	if result.Err != nil {
+		return
+	}
+
+	if t.Update {
+		result.Err = t.write(result.Path, result.Actual)
+		result.Updated = result.Err == nil
+		return
+	}
+
+	if result.Expected, result.Err = fs.ReadFile(t.FileSystem, result.Path); result.Err != nil {
+		return
+	}
+
+	if !bytes.Equal(bytes.TrimSpace(result.Expected), bytes.TrimSpace(result.Actual)) {
+		result.Err = fmt.Errorf("result does not match golden file '%s'", result.Path)
+	}
+}
+
+func (t *Tester) execute(provider *Provider, fixtures []string, result *TestResult) ([]byte, error) {
+	args, err := Args(result.Test.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := provider.statement(result.Routine.Name, args)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := t.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	// the changes of the fixtures and the routine are discarded
+	defer tx.Rollback()
+
+	for _, statement := range fixtures {
+		if _, err := tx.Exec(statement); err != nil {
+			return nil, fmt.Errorf("fixture failure: %v", err)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+
+	if !ReturnsRows(result.Routine) {
+		outcome, err := tx.Exec(query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		affected, _ := outcome.RowsAffected()
+		fmt.Fprintf(buffer, "{\"rows_affected\":%d}\n", affected)
+		return t.indent(result.Path, buffer)
+	}
+
+	rows, err := tx.Queryx(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	printer := &Printer{
+		Format: t.format(result.Path),
+	}
+
+	if err := printer.Print(buffer, rows); err != nil {
+		return nil, err
+	}
+
+	return t.indent(result.Path, buffer)
+}
+
+// fixtures returns the statements of the fixture scripts
+func (t *Tester) fixtures() ([]string, error) {
+	statements := []string{}
+
+	if t.Fixtures == nil {
+		return statements, nil
+	}
+
+	err := fs.WalkDir(t.Fixtures, ".", func(name string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.EqualFold(path.Ext(name), ".sql") {
+			return nil
+		}
+
+		file, err := t.Fixtures.Open(name)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		splitter := &Splitter{Dialect: t.DB.DriverName()}
+		statements = append(statements, splitter.Split(file)...)
+		return nil
+	})
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return statements, nil
+	}
+
+	return statements, err
+}
+
+func (t *Tester) write(name string, data []byte) error {
+	file, err := t.FileSystem.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	writer, ok := file.(io.Writer)
+	if !ok {
+		return fmt.Errorf("golden file '%s' is not writable", name)
+	}
+
+	_, err = writer.Write(data)
+	return err
+}
+
+// indent indents the JSON golden files to make their changes reviewable
+func (t *Tester) indent(name string, buffer *bytes.Buffer) ([]byte, error) {
+	if t.format(name) != FormatJSON {
+		return buffer.Bytes(), nil
+	}
+
+	indented := &bytes.Buffer{}
+
+	if err := json.Indent(indented, bytes.TrimSpace(buffer.Bytes()), "", "  "); err != nil {
+		return nil, err
+	}
+
+	indented.WriteString("\n")
+	return indented.Bytes(), nil
+}
+
+// format returns the printer format for the extension of the golden file
+func (t *Tester) format(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".ndjson":
+		return FormatNDJSON
+	case ".csv":
+		return FormatCSV
+	case ".tsv":
+		return FormatTSV
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".md":
+		return FormatMarkdown
+	case ".txt":
+		return FormatTable
+	default:
+		return FormatJSON
+	}
+}
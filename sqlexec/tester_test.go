@@ -0,0 +1,178 @@
+package sqlexec_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlexec"
+	"github.com/phogolabs/prana/storage"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tester", func() {
+	var (
+		tester *sqlexec.Tester
+		dir    string
+	)
+
+	write := func(path, content string) {
+		path = filepath.Join(dir, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0600)).To(Succeed())
+	}
+
+	read := func(path string) string {
+		data, err := ioutil.ReadFile(filepath.Join(dir, path))
+		Expect(err).NotTo(HaveOccurred())
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "prana_tester")
+		Expect(err).To(BeNil())
+
+		db, err := sqlx.Open("sqlite3", filepath.Join(dir, "prana.db"))
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+		Expect(err).To(BeNil())
+
+		routines := &bytes.Buffer{}
+		fmt.Fprintln(routines, "-- name: select-user")
+		fmt.Fprintln(routines, "-- test: params=id:int=1 expect=golden/select-jack.json")
+		fmt.Fprintln(routines, "-- test: params=id:int=2 expect=golden/select-peter.csv")
+		fmt.Fprintln(routines, "SELECT * FROM users WHERE id = :id;")
+		fmt.Fprintln(routines)
+		fmt.Fprintln(routines, "-- name: delete-users")
+		fmt.Fprintln(routines, "-- test: expect=golden/delete-users.json")
+		fmt.Fprintln(routines, "DELETE FROM users;")
+
+		write("routine/billing/users.sql", routines.String())
+		write("routine/billing/golden/select-jack.json", "[\n  {\n    \"id\": 1,\n    \"name\": \"jack\"\n  }\n]\n")
+		write("routine/billing/golden/select-peter.csv", "id,name\n2,peter\n")
+		write("routine/billing/golden/delete-users.json", "{\n  \"rows_affected\": 2\n}\n")
+		write("fixture/users.sql", "INSERT INTO users (id, name) VALUES (1, 'jack');\nINSERT INTO users (id, name) VALUES (2, 'peter');\n")
+
+		tester = &sqlexec.Tester{
+			FileSystem: storage.New(filepath.Join(dir, "routine")),
+			Fixtures:   os.DirFS(filepath.Join(dir, "fixture")),
+			DB:         db,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(tester.DB.Close()).To(Succeed())
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("runs the test cases successfully", func() {
+		results, err := tester.Test()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+
+		for _, result := range results {
+			Expect(result.Err).NotTo(HaveOccurred())
+		}
+
+		Expect(results[0].Routine.Name).To(Equal("billing.delete-users"))
+		Expect(results[0].Path).To(Equal("billing/golden/delete-users.json"))
+		Expect(results[1].Path).To(Equal("billing/golden/select-jack.json"))
+		Expect(results[2].Path).To(Equal("billing/golden/select-peter.csv"))
+	})
+
+	It("rollbacks the changes of the test cases", func() {
+		_, err := tester.Test()
+		Expect(err).NotTo(HaveOccurred())
+
+		count := -1
+		Expect(tester.DB.Get(&count, "SELECT count(*) FROM users")).To(Succeed())
+		Expect(count).To(BeZero())
+	})
+
+	It("runs the test cases of given routines", func() {
+		results, err := tester.Test("select-user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(2))
+	})
+
+	Context("when the result does not match the golden file", func() {
+		BeforeEach(func() {
+			write("routine/billing/golden/select-peter.csv", "id,name\n2,john\n")
+		})
+
+		It("returns a failure", func() {
+			results, err := tester.Test("select-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Err).NotTo(HaveOccurred())
+			Expect(results[1].Err).To(MatchError("result does not match golden file 'billing/golden/select-peter.csv'"))
+			Expect(string(results[1].Expected)).To(Equal("id,name\n2,john\n"))
+			Expect(string(results[1].Actual)).To(Equal("id,name\n2,peter\n"))
+		})
+
+		Context("when the golden files should be updated", func() {
+			BeforeEach(func() {
+				tester.Update = true
+			})
+
+			It("rewrites the golden files", func() {
+				results, err := tester.Test("select-user")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(results[1].Err).NotTo(HaveOccurred())
+				Expect(results[1].Updated).To(BeTrue())
+				Expect(read("routine/billing/golden/select-peter.csv")).To(Equal("id,name\n2,peter\n"))
+			})
+		})
+	})
+
+	Context("when the golden file does not exist", func() {
+		BeforeEach(func() {
+			Expect(os.Remove(filepath.Join(dir, "routine/billing/golden/select-jack.json"))).To(Succeed())
+		})
+
+		It("returns a failure", func() {
+			results, err := tester.Test("select-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.IsNotExist(results[0].Err)).To(BeTrue())
+		})
+	})
+
+	Context("when a fixture fails", func() {
+		BeforeEach(func() {
+			write("fixture/zzz.sql", "INSERT INTO unknown VALUES (1);\n")
+		})
+
+		It("returns a failure", func() {
+			results, err := tester.Test("select-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Err).To(MatchError("fixture failure: no such table: unknown"))
+		})
+	})
+
+	Context("when the fixture directory does not exist", func() {
+		BeforeEach(func() {
+			tester.Fixtures = os.DirFS(filepath.Join(dir, "unknown"))
+		})
+
+		It("runs the test cases without fixtures", func() {
+			results, err := tester.Test("delete-users")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Err).To(MatchError("result does not match golden file 'billing/golden/delete-users.json'"))
+			Expect(string(results[0].Actual)).To(Equal("{\n  \"rows_affected\": 0\n}\n"))
+		})
+	})
+
+	Context("when the routine does not exist", func() {
+		It("returns an error", func() {
+			_, err := tester.Test("unknown")
+			Expect(err).To(MatchError("query 'unknown' not found"))
+		})
+	})
+})
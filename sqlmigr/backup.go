@@ -0,0 +1,208 @@
+package sqlmigr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/log"
+)
+
+var _ MigrationBackup = &SQLiteBackup{}
+
+// SQLiteBackup takes a snapshot of SQLite database by using VACUUM INTO.
+type SQLiteBackup struct {
+	// Dir is the directory where the snapshots are stored.
+	Dir string
+	// DB is a client to underlying database.
+	DB *sqlx.DB
+
+	path string
+}
+
+// Path returns the path of the latest snapshot.
+func (b *SQLiteBackup) Path() string {
+	return b.path
+}
+
+// Backup takes a snapshot of the database.
+func (b *SQLiteBackup) Backup() error {
+	if err := os.MkdirAll(b.Dir, 0700); err != nil {
+		return err
+	}
+
+	path, err := b.filename()
+	if err != nil {
+		return err
+	}
+
+	if _, err := b.DB.Exec("VACUUM INTO ?", path); err != nil {
+		return err
+	}
+
+	b.path = path
+	return nil
+}
+
+// Restore restores the database from the latest snapshot. The content of
+// the database is replaced in a single transaction.
+func (b *SQLiteBackup) Restore() (err error) {
+	if b.path == "" {
+		return fmt.Errorf("snapshot not found")
+	}
+
+	ctx := context.Background()
+
+	// the attached database is visible only for the current connection
+	conn, err := b.DB.Connx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if ioErr := conn.Close(); err == nil {
+			err = ioErr
+		}
+	}()
+
+	if _, err = conn.ExecContext(ctx, "ATTACH DATABASE ? AS snapshot", b.path); err != nil {
+		return err
+	}
+
+	defer func() {
+		if _, xerr := conn.ExecContext(ctx, "DETACH DATABASE snapshot"); err == nil {
+			err = xerr
+		}
+	}()
+
+	keys := 0
+	if err = conn.GetContext(ctx, &keys, "PRAGMA foreign_keys"); err != nil {
+		return err
+	}
+
+	// the foreign keys cannot be changed within a transaction
+	if _, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+
+	defer func() {
+		if _, xerr := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA foreign_keys = %d", keys)); err == nil {
+			err = xerr
+		}
+	}()
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = b.restore(tx); err != nil {
+		if xerr := tx.Rollback(); xerr != nil {
+			log.WithError(xerr).Error("rollback failure")
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (b *SQLiteBackup) restore(tx *sqlx.Tx) error {
+	type object struct {
+		Type string `db:"type"`
+		Name string `db:"name"`
+		SQL  string `db:"sql"`
+	}
+
+	current := []object{}
+
+	query := "SELECT type, name FROM main.sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'"
+	if err := tx.Select(&current, query); err != nil {
+		return err
+	}
+
+	// the indexes and the triggers are dropped together with their tables
+	for _, item := range current {
+		stmt := fmt.Sprintf("DROP %s IF EXISTS main.%s", strings.ToUpper(item.Type), quote(item.Name))
+
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	snapshot := []object{}
+
+	query = "SELECT type, name, sql FROM snapshot.sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%' " +
+		"ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'index' THEN 1 WHEN 'view' THEN 2 ELSE 3 END, rowid"
+	if err := tx.Select(&snapshot, query); err != nil {
+		return err
+	}
+
+	for _, item := range snapshot {
+		if _, err := tx.Exec(item.SQL); err != nil {
+			return err
+		}
+
+		if item.Type != "table" {
+			continue
+		}
+
+		stmt := fmt.Sprintf("INSERT INTO main.%s SELECT * FROM snapshot.%s", quote(item.Name), quote(item.Name))
+
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	return b.sequence(tx)
+}
+
+func (b *SQLiteBackup) sequence(tx *sqlx.Tx) error {
+	count := 0
+
+	query := "SELECT count(*) FROM main.sqlite_master WHERE name = 'sqlite_sequence'"
+	if err := tx.Get(&count, query); err != nil || count == 0 {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM main.sqlite_sequence"); err != nil {
+		return err
+	}
+
+	query = "SELECT count(*) FROM snapshot.sqlite_master WHERE name = 'sqlite_sequence'"
+	if err := tx.Get(&count, query); err != nil || count == 0 {
+		return err
+	}
+
+	_, err := tx.Exec("INSERT INTO main.sqlite_sequence SELECT * FROM snapshot.sqlite_sequence")
+	return err
+}
+
+func (b *SQLiteBackup) filename() (string, error) {
+	name := time.Now().UTC().Format(format)
+
+	for index := 0; ; index++ {
+		path := filepath.Join(b.Dir, fmt.Sprintf("%s_snapshot.db", name))
+
+		if index > 0 {
+			path = filepath.Join(b.Dir, fmt.Sprintf("%s_snapshot_%d.db", name, index))
+		}
+
+		_, err := os.Stat(path)
+
+		switch {
+		case os.IsNotExist(err):
+			return filepath.Abs(path)
+		case err != nil:
+			return "", err
+		}
+	}
+}
+
+func quote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
@@ -0,0 +1,113 @@
+package sqlmigr_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlmigr"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SQLiteBackup", func() {
+	var (
+		backup *sqlmigr.SQLiteBackup
+		dir    string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		dir, err = ioutil.TempDir("", "prana_backup")
+		Expect(err).To(BeNil())
+
+		db, err := sqlx.Open("sqlite3", filepath.Join(dir, "prana.db"))
+		Expect(err).To(BeNil())
+
+		backup = &sqlmigr.SQLiteBackup{
+			Dir: filepath.Join(dir, "backup"),
+			DB:  db,
+		}
+
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)")
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("CREATE INDEX users_name ON users(name)")
+		Expect(err).To(BeNil())
+
+		_, err = db.Exec("INSERT INTO users (name) VALUES ('John'), ('Jane')")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		backup.DB.Close()
+	})
+
+	Describe("Backup", func() {
+		It("takes a snapshot successfully", func() {
+			Expect(backup.Backup()).To(Succeed())
+			Expect(backup.Path()).To(BeARegularFile())
+			Expect(filepath.Dir(backup.Path())).To(Equal(backup.Dir))
+		})
+
+		Context("when the snapshot is taken twice", func() {
+			It("does not override the previous snapshot", func() {
+				Expect(backup.Backup()).To(Succeed())
+				path := backup.Path()
+
+				Expect(backup.Backup()).To(Succeed())
+				Expect(backup.Path()).NotTo(Equal(path))
+				Expect(path).To(BeARegularFile())
+			})
+		})
+
+		Context("when the database is not available", func() {
+			It("returns an error", func() {
+				Expect(backup.DB.Close()).To(Succeed())
+				Expect(backup.Backup()).To(MatchError("sql: database is closed"))
+			})
+		})
+	})
+
+	Describe("Restore", func() {
+		BeforeEach(func() {
+			Expect(backup.Backup()).To(Succeed())
+
+			_, err := backup.DB.Exec("INSERT INTO users (name) VALUES ('Peter')")
+			Expect(err).To(BeNil())
+
+			_, err = backup.DB.Exec("CREATE TABLE documents (id INTEGER)")
+			Expect(err).To(BeNil())
+
+			_, err = backup.DB.Exec("DROP INDEX users_name")
+			Expect(err).To(BeNil())
+		})
+
+		It("restores the snapshot successfully", func() {
+			Expect(backup.Restore()).To(Succeed())
+
+			names := []string{}
+			Expect(backup.DB.Select(&names, "SELECT name FROM users ORDER BY id")).To(Succeed())
+			Expect(names).To(Equal([]string{"John", "Jane"}))
+
+			count := 0
+			Expect(backup.DB.Get(&count, "SELECT count(*) FROM sqlite_master WHERE name = 'documents'")).To(Succeed())
+			Expect(count).To(BeZero())
+
+			Expect(backup.DB.Get(&count, "SELECT count(*) FROM sqlite_master WHERE name = 'users_name'")).To(Succeed())
+			Expect(count).To(Equal(1))
+
+			Expect(backup.DB.Get(&count, "SELECT seq FROM sqlite_sequence WHERE name = 'users'")).To(Succeed())
+			Expect(count).To(Equal(2))
+		})
+
+		Context("when the snapshot is not taken", func() {
+			It("returns an error", func() {
+				backup = &sqlmigr.SQLiteBackup{DB: backup.DB}
+				Expect(backup.Restore()).To(MatchError("snapshot not found"))
+			})
+		})
+	})
+})
@@ -23,6 +23,9 @@ type Executor struct {
 	Runner MigrationRunner
 	// Generator generates a migration file.
 	Generator MigrationGenerator
+	// Backup takes a snapshot of the database before running the pending
+	// migrations and restores it if any of them fails. It's optional.
+	Backup MigrationBackup
 }
 
 // Setup setups the current project for database migrations by creating
@@ -78,6 +81,10 @@ func (m *Executor) Run(step int) (int, error) {
 		return run, err
 	}
 
+	if err := m.backup(step, migrations); err != nil {
+		return run, err
+	}
+
 	for index, migration := range migrations {
 		if step == 0 {
 			return run, nil
@@ -90,11 +97,11 @@ func (m *Executor) Run(step int) (int, error) {
 		m.logf("Running migration '%v'", migration)
 
 		if err := m.Runner.Run(migrations[index]); err != nil {
-			return run, err
+			return run, m.restore(err)
 		}
 
 		if err := m.Provider.Insert(migrations[index]); err != nil {
-			return run, err
+			return run, m.restore(err)
 		}
 
 		step = step - 1
@@ -104,6 +111,37 @@ func (m *Executor) Run(step int) (int, error) {
 	return run, nil
 }
 
+// Plan returns the execution plan of the pending migrations for given count
+// in the order in which Run executes them. If the count is negative number, it
+// will plan all pending migrations.
+func (m *Executor) Plan(step int) ([]*Plan, error) {
+	plans := []*Plan{}
+	migrations, err := m.Migrations()
+	if err != nil {
+		return plans, err
+	}
+
+	for _, migration := range migrations {
+		if step == 0 {
+			return plans, nil
+		}
+
+		if !migration.CreatedAt.IsZero() {
+			continue
+		}
+
+		plan, err := m.Runner.Plan(migration)
+		if err != nil {
+			return plans, err
+		}
+
+		plans = append(plans, plan)
+		step = step - 1
+	}
+
+	return plans, nil
+}
+
 // RunAll runs all pending migrations.
 func (m *Executor) RunAll() (int, error) {
 	return m.Run(-1)
@@ -160,6 +198,40 @@ func (m *Executor) Migrations() ([]*Migration, error) {
 	return m.Provider.Migrations()
 }
 
+// History returns the history of all run and reverted migrations.
+func (m *Executor) History() ([]*Event, error) {
+	return m.Provider.History()
+}
+
+func (m *Executor) backup(step int, migrations []*Migration) error {
+	if m.Backup == nil || step == 0 {
+		return nil
+	}
+
+	for _, migration := range migrations {
+		if migration.CreatedAt.IsZero() {
+			m.logf("Taking a database snapshot")
+			return m.Backup.Backup()
+		}
+	}
+
+	return nil
+}
+
+func (m *Executor) restore(err error) error {
+	if m.Backup == nil {
+		return err
+	}
+
+	m.logf("Restoring the database snapshot")
+
+	if xerr := m.Backup.Restore(); xerr != nil {
+		return fmt.Errorf("%v: restore failure: %v", err, xerr)
+	}
+
+	return err
+}
+
 func (m *Executor) logf(text string, args ...interface{}) {
 	if m.Logger != nil {
 		m.Logger.Infof(text, args...)
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/phogolabs/prana/fake"
 	"github.com/phogolabs/prana/sqlmigr"
+	"github.com/phogolabs/prana/storage"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -124,6 +127,89 @@ var _ = Describe("Executor", func() {
 		})
 	})
 
+	Describe("History", func() {
+		It("returns the history successfully", func() {
+			provider.HistoryReturns([]*sqlmigr.Event{{MigrationID: "id-123"}}, nil)
+			events, err := executor.History()
+			Expect(err).To(BeNil())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].MigrationID).To(Equal("id-123"))
+			Expect(provider.HistoryCallCount()).To(Equal(1))
+		})
+
+		Context("when the provider fails", func() {
+			It("returns the error", func() {
+				provider.HistoryReturns([]*sqlmigr.Event{}, fmt.Errorf("oh no!"))
+				events, err := executor.History()
+				Expect(err).To(MatchError("oh no!"))
+				Expect(events).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("Plan", func() {
+		var migrations []*sqlmigr.Migration
+
+		BeforeEach(func() {
+			migrations = []*sqlmigr.Migration{
+				{
+					ID:          "20060102150405",
+					Description: "First",
+					CreatedAt:   time.Now(),
+				},
+				{
+					ID:          "20070102150405",
+					Description: "Second",
+				},
+				{
+					ID:          "20080102150405",
+					Description: "Third",
+				},
+			}
+
+			provider.MigrationsReturns(migrations, nil)
+			runner.PlanStub = func(m *sqlmigr.Migration) (*sqlmigr.Plan, error) {
+				return &sqlmigr.Plan{Migration: m}, nil
+			}
+		})
+
+		It("plans all pending migrations", func() {
+			plans, err := executor.Plan(-1)
+			Expect(err).To(Succeed())
+			Expect(plans).To(HaveLen(2))
+			Expect(plans[0].Migration).To(Equal(migrations[1]))
+			Expect(plans[1].Migration).To(Equal(migrations[2]))
+
+			Expect(runner.RunCallCount()).To(BeZero())
+			Expect(provider.InsertCallCount()).To(BeZero())
+		})
+
+		It("plans the pending migrations for given count", func() {
+			plans, err := executor.Plan(1)
+			Expect(err).To(Succeed())
+			Expect(plans).To(HaveLen(1))
+			Expect(plans[0].Migration).To(Equal(migrations[1]))
+		})
+
+		Context("when the runner fails", func() {
+			It("returns the error", func() {
+				runner.PlanReturns(nil, fmt.Errorf("oh no!"))
+				plans, err := executor.Plan(-1)
+				Expect(err).To(MatchError("oh no!"))
+				Expect(plans).To(BeEmpty())
+			})
+		})
+
+		Context("when the provider fails", func() {
+			It("returns the error", func() {
+				provider.MigrationsReturns([]*sqlmigr.Migration{}, fmt.Errorf("oh no!"))
+				plans, err := executor.Plan(-1)
+				Expect(err).To(MatchError("oh no!"))
+				Expect(plans).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("Run", func() {
 		Context("when there are no migrations", func() {
 			It("does not run any migration", func() {
@@ -274,6 +360,91 @@ var _ = Describe("Executor", func() {
 					})
 				})
 			})
+
+			Context("when the backup is enabled", func() {
+				var backup *fake.MigrationBackup
+
+				BeforeEach(func() {
+					backup = &fake.MigrationBackup{}
+					executor.Backup = backup
+				})
+
+				It("takes a snapshot before running the migrations", func() {
+					runner.RunStub = func(m *sqlmigr.Migration) error {
+						Expect(backup.BackupCallCount()).To(Equal(1))
+						return nil
+					}
+
+					cnt, err := executor.Run(-1)
+					Expect(err).To(Succeed())
+					Expect(cnt).To(Equal(3))
+
+					Expect(backup.BackupCallCount()).To(Equal(1))
+					Expect(backup.RestoreCallCount()).To(BeZero())
+				})
+
+				Context("when the step is zero", func() {
+					It("does not take a snapshot", func() {
+						cnt, err := executor.Run(0)
+						Expect(err).To(Succeed())
+						Expect(cnt).To(BeZero())
+						Expect(backup.BackupCallCount()).To(BeZero())
+					})
+				})
+
+				Context("when there are no pending migrations", func() {
+					It("does not take a snapshot", func() {
+						provider.MigrationsReturns(migrations[:1], nil)
+
+						cnt, err := executor.Run(-1)
+						Expect(err).To(Succeed())
+						Expect(cnt).To(BeZero())
+						Expect(backup.BackupCallCount()).To(BeZero())
+					})
+				})
+
+				Context("when the snapshot cannot be taken", func() {
+					It("does not run the migrations", func() {
+						backup.BackupReturns(fmt.Errorf("Oh no!"))
+
+						cnt, err := executor.Run(-1)
+						Expect(err).To(MatchError("Oh no!"))
+						Expect(cnt).To(BeZero())
+						Expect(runner.RunCallCount()).To(BeZero())
+					})
+				})
+
+				Context("when the runner fails", func() {
+					It("restores the snapshot", func() {
+						runner.RunReturnsOnCall(1, fmt.Errorf("Oh no!"))
+
+						cnt, err := executor.Run(-1)
+						Expect(err).To(MatchError("Oh no!"))
+						Expect(cnt).To(Equal(1))
+						Expect(backup.RestoreCallCount()).To(Equal(1))
+					})
+
+					Context("when the restore fails", func() {
+						It("returns both errors", func() {
+							runner.RunReturns(fmt.Errorf("Oh no!"))
+							backup.RestoreReturns(fmt.Errorf("locked"))
+
+							_, err := executor.Run(-1)
+							Expect(err).To(MatchError("Oh no!: restore failure: locked"))
+						})
+					})
+				})
+
+				Context("when the insert fails", func() {
+					It("restores the snapshot", func() {
+						provider.InsertReturns(fmt.Errorf("Oh no!"))
+
+						_, err := executor.Run(-1)
+						Expect(err).To(MatchError("Oh no!"))
+						Expect(backup.RestoreCallCount()).To(Equal(1))
+					})
+				})
+			})
 		})
 
 		Context("when the provider fails", func() {
@@ -349,6 +520,51 @@ var _ = Describe("Executor", func() {
 			Expect(item).To(Equal(migrations[0]))
 		})
 
+		Context("when the setup migration is reverted", func() {
+			var db *sqlx.DB
+
+			BeforeEach(func() {
+				dir, err := ioutil.TempDir("", "prana_executor")
+				Expect(err).To(BeNil())
+
+				db, err = sqlx.Open("sqlite3", filepath.Join(dir, "prana.db"))
+				Expect(err).To(BeNil())
+
+				fileSystem := storage.New(dir)
+
+				executor = &sqlmigr.Executor{
+					Logger:    logger,
+					Provider:  &sqlmigr.Provider{FileSystem: fileSystem, DB: db},
+					Generator: &sqlmigr.Generator{FileSystem: fileSystem},
+					Runner:    &sqlmigr.Runner{FileSystem: fileSystem, DB: db},
+				}
+
+				Expect(executor.Setup()).To(Succeed())
+
+				cnt, err := executor.RunAll()
+				Expect(err).To(Succeed())
+				Expect(cnt).To(Equal(1))
+			})
+
+			AfterEach(func() {
+				Expect(db.Close()).To(Succeed())
+			})
+
+			It("keeps its revert event in the history", func() {
+				cnt, err := executor.RevertAll()
+				Expect(err).To(Succeed())
+				Expect(cnt).To(Equal(1))
+
+				events, err := executor.History()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(HaveLen(2))
+				Expect(events[0].Description).To(Equal("setup"))
+				Expect(events[0].Operation).To(Equal(sqlmigr.OperationRun))
+				Expect(events[1].Description).To(Equal("setup"))
+				Expect(events[1].Operation).To(Equal(sqlmigr.OperationRevert))
+			})
+		})
+
 		Context("when there are pending migrations", func() {
 			It("does not revert any of the pending migrations", func() {
 				migrations := []*sqlmigr.Migration{
@@ -0,0 +1,128 @@
+package sqlmigr
+
+import "sync"
+
+// Target is a named migration executor that is part of a group.
+type Target struct {
+	// Name of the target (e.g. the database URL).
+	Name string
+	// Executor executes the migration operations for this target.
+	Executor *Executor
+}
+
+// Result represents the outcome of a group operation for a single target.
+type Result struct {
+	// Target is the name of the target.
+	Target string
+	// Count is the number of run or reverted migrations.
+	Count int
+	// Migrations are the migrations of the target.
+	Migrations []*Migration
+	// Err is the error returned by the operation.
+	Err error
+	// Skipped returns true if the operation was not performed, because
+	// another target has failed.
+	Skipped bool
+}
+
+// Group runs the migration operations against a group of targets.
+type Group struct {
+	// Targets are the targets of the group.
+	Targets []*Target
+	// Concurrency is the number of targets processed at the same time. Zero
+	// or negative number processes a single target at a time.
+	Concurrency int
+	// ContinueOnError processes all targets even if some of them fail.
+	// Otherwise the targets that are not started yet are skipped.
+	ContinueOnError bool
+}
+
+// Run runs the pending migrations for given count on each target. If the
+// count is negative number, it will execute all pending migrations.
+func (g *Group) Run(step int) []*Result {
+	return g.each(func(executor *Executor, result *Result) {
+		result.Count, result.Err = executor.Run(step)
+	})
+}
+
+// Revert reverts the applied migrations for given count on each target. If
+// the count is negative number, it will revert all applied migrations.
+func (g *Group) Revert(step int) []*Result {
+	return g.each(func(executor *Executor, result *Result) {
+		result.Count, result.Err = executor.Revert(step)
+	})
+}
+
+// Migrations returns the migrations of each target.
+func (g *Group) Migrations() []*Result {
+	return g.each(func(executor *Executor, result *Result) {
+		result.Migrations, result.Err = executor.Migrations()
+	})
+}
+
+func (g *Group) each(fn func(executor *Executor, result *Result)) []*Result {
+	var (
+		results   = make([]*Result, len(g.Targets))
+		semaphore = make(chan struct{}, g.concurrency())
+		failed    = false
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+	)
+
+	for index, target := range g.Targets {
+		semaphore <- struct{}{}
+
+		result := &Result{Target: target.Name}
+		results[index] = result
+
+		mu.Lock()
+		result.Skipped = failed && !g.ContinueOnError
+		mu.Unlock()
+
+		if result.Skipped {
+			<-semaphore
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(target *Target) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+
+			fn(target.Executor, result)
+
+			if result.Err != nil {
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+		}(target)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (g *Group) concurrency() int {
+	if g.Concurrency <= 0 {
+		return 1
+	}
+
+	return g.Concurrency
+}
+
+// Failed returns the results that have failed.
+func Failed(results []*Result) []*Result {
+	failed := []*Result{}
+
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
@@ -0,0 +1,174 @@
+package sqlmigr_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/phogolabs/prana/fake"
+	"github.com/phogolabs/prana/sqlmigr"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Group", func() {
+	var (
+		group     *sqlmigr.Group
+		providers []*fake.MigrationProvider
+		runners   []*fake.MigrationRunner
+	)
+
+	BeforeEach(func() {
+		group = &sqlmigr.Group{}
+		providers = []*fake.MigrationProvider{}
+		runners = []*fake.MigrationRunner{}
+
+		for index := 0; index < 3; index++ {
+			provider := &fake.MigrationProvider{}
+			provider.MigrationsReturns([]*sqlmigr.Migration{
+				{
+					ID:          "20160102150",
+					Description: "First",
+					CreatedAt:   time.Now(),
+				},
+				{
+					ID:          "20160102151",
+					Description: "Second",
+				},
+			}, nil)
+
+			runner := &fake.MigrationRunner{}
+
+			providers = append(providers, provider)
+			runners = append(runners, runner)
+
+			group.Targets = append(group.Targets, &sqlmigr.Target{
+				Name: fmt.Sprintf("db%d", index),
+				Executor: &sqlmigr.Executor{
+					Provider: provider,
+					Runner:   runner,
+				},
+			})
+		}
+	})
+
+	Describe("Run", func() {
+		It("runs the migrations on each target", func() {
+			results := group.Run(-1)
+			Expect(results).To(HaveLen(3))
+
+			for index, result := range results {
+				Expect(result.Target).To(Equal(fmt.Sprintf("db%d", index)))
+				Expect(result.Count).To(Equal(1))
+				Expect(result.Err).To(BeNil())
+				Expect(result.Skipped).To(BeFalse())
+				Expect(runners[index].RunCallCount()).To(Equal(1))
+			}
+
+			Expect(sqlmigr.Failed(results)).To(BeEmpty())
+		})
+
+		Context("when the concurrency is more than one", func() {
+			BeforeEach(func() {
+				group.Concurrency = 2
+			})
+
+			It("runs the migrations on each target", func() {
+				results := group.Run(-1)
+				Expect(results).To(HaveLen(3))
+
+				for index, result := range results {
+					Expect(result.Count).To(Equal(1))
+					Expect(runners[index].RunCallCount()).To(Equal(1))
+				}
+			})
+		})
+
+		Context("when a target fails", func() {
+			BeforeEach(func() {
+				runners[1].RunReturns(fmt.Errorf("oh no!"))
+			})
+
+			It("skips the rest of the targets", func() {
+				results := group.Run(-1)
+				Expect(results).To(HaveLen(3))
+
+				Expect(results[0].Err).To(BeNil())
+				Expect(results[1].Err).To(MatchError("oh no!"))
+				Expect(results[2].Skipped).To(BeTrue())
+				Expect(runners[2].RunCallCount()).To(BeZero())
+
+				failed := sqlmigr.Failed(results)
+				Expect(failed).To(HaveLen(1))
+				Expect(failed[0].Target).To(Equal("db1"))
+			})
+
+			Context("when the group continues on error", func() {
+				BeforeEach(func() {
+					group.ContinueOnError = true
+				})
+
+				It("runs the migrations on the rest of the targets", func() {
+					results := group.Run(-1)
+					Expect(results).To(HaveLen(3))
+
+					Expect(results[1].Err).To(MatchError("oh no!"))
+					Expect(results[2].Skipped).To(BeFalse())
+					Expect(results[2].Count).To(Equal(1))
+					Expect(runners[2].RunCallCount()).To(Equal(1))
+				})
+			})
+		})
+	})
+
+	Describe("Revert", func() {
+		It("reverts the migrations on each target", func() {
+			results := group.Revert(-1)
+			Expect(results).To(HaveLen(3))
+
+			for index, result := range results {
+				Expect(result.Count).To(Equal(1))
+				Expect(result.Err).To(BeNil())
+				Expect(runners[index].RevertCallCount()).To(Equal(1))
+			}
+		})
+
+		Context("when a target fails", func() {
+			BeforeEach(func() {
+				runners[0].RevertReturns(fmt.Errorf("oh no!"))
+			})
+
+			It("skips the rest of the targets", func() {
+				results := group.Revert(-1)
+				Expect(results[0].Err).To(MatchError("oh no!"))
+				Expect(results[1].Skipped).To(BeTrue())
+				Expect(results[2].Skipped).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("Migrations", func() {
+		It("returns the migrations of each target", func() {
+			results := group.Migrations()
+			Expect(results).To(HaveLen(3))
+
+			for _, result := range results {
+				Expect(result.Migrations).To(HaveLen(2))
+				Expect(result.Err).To(BeNil())
+			}
+		})
+
+		Context("when the provider fails", func() {
+			BeforeEach(func() {
+				group.ContinueOnError = true
+				providers[2].MigrationsReturns(nil, fmt.Errorf("oh no!"))
+			})
+
+			It("returns the error", func() {
+				results := group.Migrations()
+				Expect(results[0].Err).To(BeNil())
+				Expect(results[2].Err).To(MatchError("oh no!"))
+			})
+		})
+	})
+})
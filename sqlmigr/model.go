@@ -16,6 +16,7 @@ import (
 //go:generate counterfeiter -fake-name MigrationRunner -o ../fake/migration_runner.go . MigrationRunner
 //go:generate counterfeiter -fake-name MigrationProvider -o ../fake/migration_provider.go . MigrationProvider
 //go:generate counterfeiter -fake-name MigrationGenerator -o ../fake/migration_generator.go . MigrationGenerator
+//go:generate counterfeiter -fake-name MigrationBackup -o ../fake/migration_backup.go . MigrationBackup
 
 var (
 	format = "20060102150405"
@@ -23,6 +24,13 @@ var (
 	every  = "sql"
 )
 
+const (
+	// OperationRun is the history operation of executed migration
+	OperationRun = "run"
+	// OperationRevert is the history operation of reverted migration
+	OperationRevert = "revert"
+)
+
 var (
 	// setup migration
 	setup = &Migration{
@@ -50,6 +58,8 @@ type MigrationRunner interface {
 	Run(item *Migration) error
 	// Revert reverts a given sqlmigr item.
 	Revert(item *Migration) error
+	// Plan returns the execution plan of a given sqlmigr item.
+	Plan(item *Migration) (*Plan, error)
 }
 
 // MigrationProvider provides all items.
@@ -62,6 +72,8 @@ type MigrationProvider interface {
 	Delete(item *Migration) error
 	// Exists returns true if the sqlmigr exists
 	Exists(item *Migration) bool
+	// History returns all run and revert events ordered by their time.
+	History() ([]*Event, error)
 }
 
 // MigrationGenerator generates a migration item file.
@@ -72,6 +84,14 @@ type MigrationGenerator interface {
 	Write(m *Migration, content *Content) error
 }
 
+// MigrationBackup takes and restores a snapshot of the database.
+type MigrationBackup interface {
+	// Backup takes a snapshot of the database.
+	Backup() error
+	// Restore restores the database from the latest snapshot.
+	Restore() error
+}
+
 // Content represents a migration content.
 type Content struct {
 	// UpCommand is the content for upgrade operation.
@@ -137,6 +157,37 @@ func (m *Migration) Equal(migration *Migration) bool {
 	return m.ID == migration.ID && m.Description == migration.Description
 }
 
+// Event represents a single run or revert of a migration recorded in the
+// migrations history.
+type Event struct {
+	// MigrationID is the id of the migration
+	MigrationID string `db:"migration_id"`
+	// Description is the short description of the migration.
+	Description string `db:"description"`
+	// Operation is the performed operation (run or revert).
+	Operation string `db:"operation"`
+	// Actor is the user who performed the operation.
+	Actor string `db:"actor"`
+	// Host is the machine from which the operation was performed.
+	Host string `db:"host"`
+	// Version is the prana version that performed the operation.
+	Version string `db:"version"`
+	// CreatedAt returns the time of the operation.
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Plan represents the execution plan of a pending migration.
+type Plan struct {
+	// Migration is the planned migration.
+	Migration *Migration
+	// Files are the migration files that contain the up routine.
+	Files []string
+	// Statements are the statements that will be executed.
+	Statements []string
+	// Transactional returns true if the statements are applied atomically.
+	Transactional bool
+}
+
 // Parse parses a given file path to a sqlmigr item.
 func Parse(path string) (*Migration, error) {
 	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
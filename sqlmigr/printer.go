@@ -58,3 +58,190 @@ func Ftable(w io.Writer, migrations []*Migration) {
 
 	fmt.Fprintln(w, table)
 }
+
+// FlogEvents prints the history events as fields
+func FlogEvents(logger log.Logger, events []*Event) {
+	for _, e := range events {
+		fields := log.Map{
+			"Id":          e.MigrationID,
+			"Description": e.Description,
+			"Operation":   e.Operation,
+			"Actor":       e.Actor,
+			"Host":        e.Host,
+			"Version":     e.Version,
+			"CreatedAt":   e.CreatedAt.Format(time.UnixDate),
+		}
+
+		logger.WithFields(fields).Info("Event")
+	}
+}
+
+// FtableEvents prints the history events as table
+func FtableEvents(w io.Writer, events []*Event) {
+	table := uitable.New()
+	table.MaxColWidth = 50
+
+	table.AddRow("Id", "Description", "Operation", "Actor", "Host", "Version", "Created At")
+
+	for _, e := range events {
+		operation := color.GreenString(e.Operation)
+
+		if e.Operation == OperationRevert {
+			operation = color.YellowString(e.Operation)
+		}
+
+		table.AddRow(e.MigrationID, e.Description, operation, e.Actor, e.Host, e.Version, e.CreatedAt.Format(time.UnixDate))
+	}
+
+	fmt.Fprintln(w, table)
+}
+
+// FlogPlans prints the migration plans as fields
+func FlogPlans(logger log.Logger, plans []*Plan) {
+	for _, p := range plans {
+		fields := log.Map{
+			"Id":            p.Migration.ID,
+			"Description":   p.Migration.Description,
+			"Files":         strings.Join(p.Files, ", "),
+			"Statements":    len(p.Statements),
+			"Transactional": p.Transactional,
+		}
+
+		logger.WithFields(fields).Info("Plan")
+	}
+}
+
+// FtablePlans prints the migration plans as table
+func FtablePlans(w io.Writer, plans []*Plan) {
+	table := uitable.New()
+	table.MaxColWidth = 50
+	table.Wrap = true
+
+	for _, p := range plans {
+		transaction := color.GreenString("yes")
+
+		if !p.Transactional {
+			transaction = color.RedString("no")
+		}
+
+		table.AddRow("Id", p.Migration.ID)
+		table.AddRow("Description", p.Migration.Description)
+		table.AddRow("Files", strings.Join(p.Files, ", "))
+		table.AddRow("Statements", len(p.Statements))
+		table.AddRow("Transaction", transaction)
+		table.AddRow("")
+	}
+
+	fmt.Fprintln(w, table)
+}
+
+// Fscript prints the statements of the migration plans as SQL script
+func Fscript(w io.Writer, plans []*Plan) {
+	for _, p := range plans {
+		fmt.Fprintf(w, "-- migration: %v", p.Migration)
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "-- files: %s", strings.Join(p.Files, ", "))
+		fmt.Fprintln(w)
+
+		for _, query := range p.Statements {
+			fmt.Fprintln(w, strings.TrimSpace(query))
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// FlogResults prints the group results as fields
+func FlogResults(logger log.Logger, results []*Result) {
+	for _, r := range results {
+		executed, pending := count(r.Migrations)
+
+		fields := log.Map{
+			"Target":   r.Target,
+			"Status":   status(r),
+			"Count":    r.Count,
+			"Executed": executed,
+			"Pending":  pending,
+		}
+
+		entry := logger.WithFields(fields)
+
+		if r.Err != nil {
+			entry = entry.WithError(r.Err)
+		}
+
+		entry.Info("Result")
+	}
+}
+
+// FtableResults prints the results of group run or revert as table
+func FtableResults(w io.Writer, results []*Result) {
+	table := uitable.New()
+	table.MaxColWidth = 80
+	table.Wrap = true
+
+	table.AddRow("Target", "Status", "Count", "Error")
+
+	for _, r := range results {
+		table.AddRow(r.Target, colorize(status(r)), r.Count, message(r.Err))
+	}
+
+	fmt.Fprintln(w, table)
+}
+
+// FtableStatus prints the migration status of each group target as table
+func FtableStatus(w io.Writer, results []*Result) {
+	table := uitable.New()
+	table.MaxColWidth = 80
+	table.Wrap = true
+
+	table.AddRow("Target", "Status", "Executed", "Pending", "Error")
+
+	for _, r := range results {
+		executed, pending := count(r.Migrations)
+		table.AddRow(r.Target, colorize(status(r)), executed, pending, message(r.Err))
+	}
+
+	fmt.Fprintln(w, table)
+}
+
+func count(migrations []*Migration) (int, int) {
+	executed := 0
+
+	for _, m := range migrations {
+		if !m.CreatedAt.IsZero() {
+			executed++
+		}
+	}
+
+	return executed, len(migrations) - executed
+}
+
+func status(r *Result) string {
+	switch {
+	case r.Skipped:
+		return "skipped"
+	case r.Err != nil:
+		return "failed"
+	default:
+		return "ok"
+	}
+}
+
+func colorize(status string) string {
+	switch status {
+	case "skipped":
+		return color.YellowString(status)
+	case "failed":
+		return color.RedString(status)
+	default:
+		return color.GreenString(status)
+	}
+}
+
+func message(err error) string {
+	if err == nil {
+		return "--"
+	}
+
+	return err.Error()
+}
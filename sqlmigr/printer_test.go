@@ -2,6 +2,7 @@ package sqlmigr_test
 
 import (
 	"bytes"
+	"fmt"
 	"time"
 
 	"github.com/phogolabs/prana/fake"
@@ -86,4 +87,189 @@ var _ = Describe("Printer", func() {
 			})
 		})
 	})
+
+	Context("FlogEvents", func() {
+		var logger *fake.Logger
+
+		BeforeEach(func() {
+			logger = &fake.Logger{}
+			logger.WithFieldsReturns(logger)
+		})
+
+		It("logs the events", func() {
+			events := []*sqlmigr.Event{
+				{
+					MigrationID: "20060102150405",
+					Description: "First",
+					Operation:   sqlmigr.OperationRevert,
+					Actor:       "root",
+					CreatedAt:   time.Now(),
+				},
+			}
+
+			sqlmigr.FlogEvents(logger, events)
+			Expect(logger.WithFieldsCallCount()).To(Equal(1))
+
+			fields := logger.WithFieldsArgsForCall(0)
+			Expect(fields).To(HaveKeyWithValue("Id", "20060102150405"))
+			Expect(fields).To(HaveKeyWithValue("Operation", "revert"))
+			Expect(fields).To(HaveKeyWithValue("Actor", "root"))
+		})
+	})
+
+	Context("FtableEvents", func() {
+		It("logs the events", func() {
+			events := []*sqlmigr.Event{
+				{
+					MigrationID: "20060102150405",
+					Description: "First",
+					Operation:   sqlmigr.OperationRun,
+					Actor:       "root",
+					Host:        "localhost",
+					Version:     "1.0",
+					CreatedAt:   time.Now(),
+				},
+			}
+
+			w := &bytes.Buffer{}
+			sqlmigr.FtableEvents(w, events)
+
+			content := w.String()
+			Expect(content).To(ContainSubstring("Operation"))
+			Expect(content).To(ContainSubstring("20060102150405"))
+			Expect(content).To(ContainSubstring("run"))
+			Expect(content).To(ContainSubstring("root"))
+			Expect(content).To(ContainSubstring("localhost"))
+		})
+	})
+
+	Context("FtablePlans", func() {
+		It("logs the plans", func() {
+			plans := []*sqlmigr.Plan{
+				{
+					Migration:     migrations[0],
+					Files:         []string{"20060102150405_first.sql", "20060102150405_first_mysql.sql"},
+					Statements:    []string{"CREATE TABLE users(id INT);", "DROP TABLE users;"},
+					Transactional: false,
+				},
+			}
+
+			w := &bytes.Buffer{}
+			sqlmigr.FtablePlans(w, plans)
+
+			content := w.String()
+			Expect(content).To(ContainSubstring("20060102150405"))
+			Expect(content).To(ContainSubstring("20060102150405_first.sql"))
+			Expect(content).To(ContainSubstring("20060102150405_first_mysql"))
+			Expect(content).To(ContainSubstring("Statements"))
+			Expect(content).To(ContainSubstring("2"))
+			Expect(content).To(ContainSubstring("no"))
+		})
+	})
+
+	Context("FlogPlans", func() {
+		It("logs the plans", func() {
+			logger := &fake.Logger{}
+			logger.WithFieldsReturns(logger)
+
+			plans := []*sqlmigr.Plan{
+				{
+					Migration:     migrations[0],
+					Statements:    []string{"CREATE TABLE users(id INT);"},
+					Transactional: true,
+				},
+			}
+
+			sqlmigr.FlogPlans(logger, plans)
+			Expect(logger.WithFieldsCallCount()).To(Equal(1))
+
+			fields := logger.WithFieldsArgsForCall(0)
+			Expect(fields).To(HaveKeyWithValue("Id", migrations[0].ID))
+			Expect(fields).To(HaveKeyWithValue("Statements", 1))
+			Expect(fields).To(HaveKeyWithValue("Transactional", true))
+		})
+	})
+
+	Context("Fscript", func() {
+		It("prints the statements", func() {
+			plans := []*sqlmigr.Plan{
+				{
+					Migration:  migrations[0],
+					Files:      []string{"20060102150405_first.sql"},
+					Statements: []string{"CREATE TABLE users(id INT);\n"},
+				},
+			}
+
+			w := &bytes.Buffer{}
+			sqlmigr.Fscript(w, plans)
+
+			content := w.String()
+			Expect(content).To(ContainSubstring("-- migration: 20060102150405_First"))
+			Expect(content).To(ContainSubstring("-- files: 20060102150405_first.sql"))
+			Expect(content).To(ContainSubstring("CREATE TABLE users(id INT);\n\n"))
+		})
+	})
+
+	Context("FtableResults", func() {
+		It("prints the results", func() {
+			results := []*sqlmigr.Result{
+				{Target: "sqlite3://one.db", Count: 2},
+				{Target: "sqlite3://two.db", Err: fmt.Errorf("oh no!")},
+				{Target: "sqlite3://three.db", Skipped: true},
+			}
+
+			w := &bytes.Buffer{}
+			sqlmigr.FtableResults(w, results)
+
+			content := w.String()
+			Expect(content).To(ContainSubstring("Target"))
+			Expect(content).To(ContainSubstring("sqlite3://one.db"))
+			Expect(content).To(ContainSubstring("ok"))
+			Expect(content).To(ContainSubstring("failed"))
+			Expect(content).To(ContainSubstring("oh no!"))
+			Expect(content).To(ContainSubstring("skipped"))
+		})
+	})
+
+	Context("FtableStatus", func() {
+		It("prints the status of each target", func() {
+			results := []*sqlmigr.Result{
+				{Target: "sqlite3://one.db", Migrations: migrations},
+			}
+
+			w := &bytes.Buffer{}
+			sqlmigr.FtableStatus(w, results)
+
+			content := w.String()
+			Expect(content).To(ContainSubstring("Executed"))
+			Expect(content).To(ContainSubstring("Pending"))
+			Expect(content).To(ContainSubstring("sqlite3://one.db"))
+		})
+	})
+
+	Context("FlogResults", func() {
+		It("logs the results", func() {
+			logger := &fake.Logger{}
+			logger.WithFieldsReturns(logger)
+			logger.WithErrorReturns(logger)
+
+			results := []*sqlmigr.Result{
+				{Target: "sqlite3://one.db", Migrations: migrations},
+				{Target: "sqlite3://two.db", Err: fmt.Errorf("oh no!")},
+			}
+
+			sqlmigr.FlogResults(logger, results)
+			Expect(logger.WithFieldsCallCount()).To(Equal(2))
+			Expect(logger.WithErrorCallCount()).To(Equal(1))
+
+			fields := logger.WithFieldsArgsForCall(0)
+			Expect(fields).To(HaveKeyWithValue("Target", "sqlite3://one.db"))
+			Expect(fields).To(HaveKeyWithValue("Status", "ok"))
+			Expect(fields).To(HaveKeyWithValue("Executed", 1))
+			Expect(fields).To(HaveKeyWithValue("Pending", 0))
+
+			fields = logger.WithFieldsArgsForCall(1)
+			Expect(fields).To(HaveKeyWithValue("Status", "failed"))
+		})
+	})
 })
@@ -6,7 +6,9 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -20,6 +22,14 @@ type Provider struct {
 	FileSystem FileSystem
 	// DB is a client to underlying database.
 	DB *sqlx.DB
+	// Actor is the user recorded in the migrations history. If it's empty
+	// the current OS user is used.
+	Actor string
+	// Version is the prana version recorded in the migrations history.
+	Version string
+	// Schema is the database schema of the migrations tables. If it's
+	// empty the tables are resolved by the database search path.
+	Schema string
 }
 
 // Migrations returns the project migrations.
@@ -135,7 +145,7 @@ func (m *Provider) Insert(item *Migration) error {
 		return err
 	}
 
-	return nil
+	return m.record(OperationRun, item)
 }
 
 // Delete deletes applied sqlmigr item from sqlmigrs table.
@@ -145,11 +155,24 @@ func (m *Provider) Delete(item *Migration) error {
 	builder.WriteString("WHERE id = ?")
 
 	query := m.DB.Rebind(builder.String())
-	if _, err := m.DB.Exec(query, item.ID); err != nil {
+
+	result, err := m.DB.Exec(query, item.ID)
+	if err != nil {
+		// the setup migration drops the migrations table, so its revert
+		// event is recorded in the history that is kept
+		if IsNotExist(err) && item.ID == setup.ID {
+			return m.record(OperationRevert, item)
+		}
+
 		return err
 	}
 
-	return nil
+	// the event is recorded only for a migration that has been applied
+	if count, err := result.RowsAffected(); err != nil || count == 0 {
+		return err
+	}
+
+	return m.record(OperationRevert, item)
 }
 
 // Exists returns true if the sqlmigr exists
@@ -163,6 +186,112 @@ func (m *Provider) Exists(item *Migration) bool {
 	return count == 1
 }
 
+// History returns all run and revert events ordered by their time. The
+// events that have the same time are ordered as they were recorded.
+func (m *Provider) History() ([]*Event, error) {
+	query := &bytes.Buffer{}
+	query.WriteString("SELECT migration_id, description, operation, actor, host, version, created_at ")
+	query.WriteString("FROM " + m.history() + " ")
+	query.WriteString("ORDER BY created_at ASC, id ASC")
+
+	events := []*Event{}
+
+	if err := m.DB.Select(&events, query.String()); err != nil && !IsNotExist(err) {
+		return []*Event{}, err
+	}
+
+	return events, nil
+}
+
+func (m *Provider) record(operation string, item *Migration) error {
+	ddl := &bytes.Buffer{}
+	fmt.Fprintln(ddl, "CREATE TABLE IF NOT EXISTS "+m.history()+" (")
+	fmt.Fprintln(ddl, " id           "+m.serial()+",")
+	fmt.Fprintln(ddl, " migration_id VARCHAR(14) NOT NULL,")
+	fmt.Fprintln(ddl, " description  TEXT        NOT NULL,")
+	fmt.Fprintln(ddl, " operation    VARCHAR(10) NOT NULL,")
+	fmt.Fprintln(ddl, " actor        TEXT        NOT NULL,")
+	fmt.Fprintln(ddl, " host         TEXT        NOT NULL,")
+	fmt.Fprintln(ddl, " version      TEXT        NOT NULL,")
+	fmt.Fprintln(ddl, " created_at   TIMESTAMP   NOT NULL")
+	fmt.Fprintln(ddl, ");")
+
+	if _, err := m.DB.Exec(ddl.String()); err != nil {
+		return err
+	}
+
+	event := &Event{
+		MigrationID: item.ID,
+		Description: item.Description,
+		Operation:   operation,
+		Actor:       m.actor(),
+		Host:        m.host(),
+		Version:     m.version(),
+		CreatedAt:   time.Now(),
+	}
+
+	builder := &bytes.Buffer{}
+	builder.WriteString("INSERT INTO " + m.history() + "(migration_id, description, operation, actor, host, version, created_at) ")
+	builder.WriteString("VALUES (?, ?, ?, ?, ?, ?, ?)")
+
+	query := m.DB.Rebind(builder.String())
+	_, err := m.DB.Exec(query,
+		event.MigrationID,
+		event.Description,
+		event.Operation,
+		event.Actor,
+		event.Host,
+		event.Version,
+		event.CreatedAt,
+	)
+
+	return err
+}
+
+// serial returns the definition of the auto-incremented primary key
+func (m *Provider) serial() string {
+	switch m.DB.DriverName() {
+	case "postgres":
+		return "SERIAL      PRIMARY KEY"
+	case "mysql":
+		return "INTEGER     NOT NULL AUTO_INCREMENT PRIMARY KEY"
+	default:
+		return "INTEGER     PRIMARY KEY AUTOINCREMENT"
+	}
+}
+
+func (m *Provider) actor() string {
+	if m.Actor != "" {
+		return m.Actor
+	}
+
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+
+	return "unknown"
+}
+
+func (m *Provider) host() string {
+	if name, err := os.Hostname(); err == nil {
+		return name
+	}
+
+	return "unknown"
+}
+
+func (m *Provider) version() string {
+	if m.Version != "" {
+		return m.Version
+	}
+
+	return "unknown"
+}
+
 func (m *Provider) merge(remote, local []*Migration) ([]*Migration, error) {
 	result := local
 
@@ -186,6 +315,22 @@ func (m *Provider) merge(remote, local []*Migration) ([]*Migration, error) {
 }
 
 func (m *Provider) table() string {
+	return m.qualify(m.name())
+}
+
+func (m *Provider) history() string {
+	return m.qualify(m.name() + "_history")
+}
+
+func (m *Provider) qualify(name string) string {
+	if m.Schema == "" || strings.Contains(name, ".") {
+		return name
+	}
+
+	return quote(m.Schema) + "." + quote(name)
+}
+
+func (m *Provider) name() string {
 	for _, path := range setup.Filenames() {
 		file, err := m.FileSystem.Open(path)
 		if err != nil {
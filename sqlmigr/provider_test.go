@@ -121,6 +121,30 @@ var _ = Describe("Provider", func() {
 			Expect(items[1].Description).To(Equal("trigger"))
 		})
 
+		It("records the event in the history", func() {
+			provider.Actor = "root"
+			provider.Version = "1.0"
+
+			item := sqlmigr.Migration{
+				ID:          "20070102150405",
+				Description: "trigger",
+			}
+
+			Expect(provider.Insert(&item)).To(Succeed())
+
+			events, err := provider.History()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+
+			Expect(events[0].MigrationID).To(Equal("20070102150405"))
+			Expect(events[0].Description).To(Equal("trigger"))
+			Expect(events[0].Operation).To(Equal(sqlmigr.OperationRun))
+			Expect(events[0].Actor).To(Equal("root"))
+			Expect(events[0].Version).To(Equal("1.0"))
+			Expect(events[0].Host).NotTo(BeEmpty())
+			Expect(events[0].CreatedAt.IsZero()).To(BeFalse())
+		})
+
 		Context("when the database is not available", func() {
 			JustBeforeEach(func() {
 				Expect(provider.DB.Close()).To(Succeed())
@@ -135,6 +159,38 @@ var _ = Describe("Provider", func() {
 				Expect(provider.Insert(&item)).To(MatchError("sql: database is closed"))
 			})
 		})
+		Context("when the schema is set", func() {
+			JustBeforeEach(func() {
+				// the attached database is visible only for the current connection
+				provider.DB.SetMaxOpenConns(1)
+				provider.Schema = "tenant"
+
+				_, err := provider.DB.Exec("ATTACH DATABASE ? AS tenant", filepath.Join(dir, "tenant.db"))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = provider.DB.Exec("CREATE TABLE tenant.migrations (id TEXT NOT NULL PRIMARY KEY, description TEXT NOT NULL, created_at TIMESTAMP NOT NULL)")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("inserts the item in the schema migrations table", func() {
+				item := sqlmigr.Migration{
+					ID:          "20070102150405",
+					Description: "trigger",
+				}
+
+				Expect(provider.Insert(&item)).To(Succeed())
+
+				count := 0
+				Expect(provider.DB.Get(&count, "SELECT count(*) FROM tenant.migrations")).To(Succeed())
+				Expect(count).To(Equal(1))
+
+				Expect(provider.DB.Get(&count, "SELECT count(*) FROM main.migrations")).To(Succeed())
+				Expect(count).To(Equal(1))
+
+				Expect(provider.DB.Get(&count, "SELECT count(*) FROM tenant.migrations_history")).To(Succeed())
+				Expect(count).To(Equal(1))
+			})
+		})
 	})
 
 	Describe("Delete", func() {
@@ -153,6 +209,75 @@ var _ = Describe("Provider", func() {
 			Expect(items).To(BeEmpty())
 		})
 
+		It("keeps the revert event in the history", func() {
+			item := sqlmigr.Migration{
+				ID:          "20060102150405",
+				Description: "schema",
+			}
+
+			Expect(provider.Delete(&item)).To(Succeed())
+
+			events, err := provider.History()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+
+			Expect(events[0].MigrationID).To(Equal("20060102150405"))
+			Expect(events[0].Operation).To(Equal(sqlmigr.OperationRevert))
+			Expect(events[0].Actor).NotTo(BeEmpty())
+			Expect(events[0].Version).To(Equal("unknown"))
+		})
+
+		Context("when the migrations table does not exist", func() {
+			JustBeforeEach(func() {
+				_, err := provider.DB.Exec("DROP TABLE migrations")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("records the revert event of the setup migration", func() {
+				item := sqlmigr.Migration{
+					ID:          "00060524000000",
+					Description: "setup",
+				}
+
+				Expect(provider.Delete(&item)).To(Succeed())
+
+				events, err := provider.History()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(HaveLen(1))
+				Expect(events[0].MigrationID).To(Equal("00060524000000"))
+				Expect(events[0].Operation).To(Equal(sqlmigr.OperationRevert))
+			})
+
+			It("does not record the event of another migration", func() {
+				item := sqlmigr.Migration{
+					ID:          "20060102150405",
+					Description: "schema",
+				}
+
+				err := provider.Delete(&item)
+				Expect(sqlmigr.IsNotExist(err)).To(BeTrue())
+
+				events, err := provider.History()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(BeEmpty())
+			})
+		})
+
+		Context("when the migration is not applied", func() {
+			It("does not record the event in the history", func() {
+				item := sqlmigr.Migration{
+					ID:          "20070102150405",
+					Description: "trigger",
+				}
+
+				Expect(provider.Delete(&item)).To(Succeed())
+
+				events, err := provider.History()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(BeEmpty())
+			})
+		})
+
 		Context("when the database is not available", func() {
 			JustBeforeEach(func() {
 				Expect(provider.DB.Close()).To(Succeed())
@@ -168,6 +293,65 @@ var _ = Describe("Provider", func() {
 		})
 	})
 
+	Describe("History", func() {
+		It("returns the events ordered by time", func() {
+			item := &sqlmigr.Migration{
+				ID:          "20070102150405",
+				Description: "trigger",
+			}
+
+			Expect(provider.Insert(item)).To(Succeed())
+			Expect(provider.Delete(item)).To(Succeed())
+
+			events, err := provider.History()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(2))
+			Expect(events[0].Operation).To(Equal(sqlmigr.OperationRun))
+			Expect(events[1].Operation).To(Equal(sqlmigr.OperationRevert))
+		})
+
+		It("returns the events with the same time in their order", func() {
+			item := &sqlmigr.Migration{
+				ID:          "20070102150405",
+				Description: "trigger",
+			}
+
+			Expect(provider.Insert(item)).To(Succeed())
+			Expect(provider.Delete(item)).To(Succeed())
+			Expect(provider.Insert(item)).To(Succeed())
+
+			_, err := provider.DB.Exec("UPDATE migrations_history SET created_at = ?", time.Now())
+			Expect(err).NotTo(HaveOccurred())
+
+			events, err := provider.History()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(3))
+			Expect(events[0].Operation).To(Equal(sqlmigr.OperationRun))
+			Expect(events[1].Operation).To(Equal(sqlmigr.OperationRevert))
+			Expect(events[2].Operation).To(Equal(sqlmigr.OperationRun))
+		})
+
+		Context("when the history table does not exist", func() {
+			It("returns an empty history", func() {
+				events, err := provider.History()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(events).To(BeEmpty())
+			})
+		})
+
+		Context("when the database is not available", func() {
+			JustBeforeEach(func() {
+				Expect(provider.DB.Close()).To(Succeed())
+			})
+
+			It("returns an error", func() {
+				events, err := provider.History()
+				Expect(err).To(MatchError("sql: database is closed"))
+				Expect(events).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("Migrations", func() {
 		It("returns the sqlmigrs successfully", func() {
 			path := filepath.Join(dir, "20070102150405_setup.sql")
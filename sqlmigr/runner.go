@@ -2,7 +2,9 @@ package sqlmigr
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
+	"regexp"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/phogolabs/log"
@@ -11,12 +13,20 @@ import (
 
 var _ MigrationRunner = &Runner{}
 
+// MySQL commits the transaction implicitly before and after a DDL statement
+var ddlRgxp = regexp.MustCompile(`(?i)^(\s*--[^\n]*\n)*\s*(CREATE|ALTER|DROP|RENAME|TRUNCATE)\s`)
+
 // Runner runs or reverts a given migration  item.
 type Runner struct {
 	// FileSystem represents the project directory file system.
 	FileSystem FileSystem
 	// DB is a client to underlying database.
 	DB *sqlx.DB
+	// Schema is the database schema set as search path of the migration
+	// transaction. The public schema follows it in the search path, so the
+	// shared objects (e.g. extensions) are still found. It's supported only by
+	// PostgreSQL.
+	Schema string
 }
 
 // Run runs a given migration  item.
@@ -29,8 +39,25 @@ func (r *Runner) Revert(m *Migration) error {
 	return r.exec("down", m)
 }
 
+// Plan returns the execution plan of a given migration item.
+func (r *Runner) Plan(m *Migration) (*Plan, error) {
+	files, statements, err := r.routine("up", m)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		Migration:     m,
+		Files:         files,
+		Statements:    statements,
+		Transactional: r.transactional(statements),
+	}
+
+	return plan, nil
+}
+
 func (r *Runner) exec(step string, m *Migration) error {
-	statements, err := r.routine(step, m)
+	_, statements, err := r.routine(step, m)
 	if err != nil {
 		return err
 	}
@@ -40,6 +67,14 @@ func (r *Runner) exec(step string, m *Migration) error {
 		return err
 	}
 
+	if err := r.search(tx); err != nil {
+		if xerr := tx.Rollback(); xerr != nil {
+			log.WithError(xerr).Error("rollback failure")
+		}
+
+		return err
+	}
+
 	for _, query := range statements {
 		if _, err := tx.Exec(query); err != nil {
 			if xerr := tx.Rollback(); xerr != nil {
@@ -56,8 +91,23 @@ func (r *Runner) exec(step string, m *Migration) error {
 	return tx.Commit()
 }
 
-func (r *Runner) routine(name string, m *Migration) ([]string, error) {
+func (r *Runner) search(tx *sql.Tx) error {
+	if r.Schema == "" {
+		return nil
+	}
+
+	if driver := r.DB.DriverName(); driver != "postgres" {
+		return fmt.Errorf("schema is not supported by '%s' driver", driver)
+	}
+
+	// the search path is reset when the transaction ends
+	_, err := tx.Exec("SET LOCAL search_path TO " + quote(r.Schema) + ", public")
+	return err
+}
+
+func (r *Runner) routine(name string, m *Migration) ([]string, []string, error) {
 	statements := make(map[string][]string, 2)
+	files := make(map[string][]string, 2)
 	filenames := m.Filenames()
 
 	if name == "down" {
@@ -67,28 +117,43 @@ func (r *Runner) routine(name string, m *Migration) ([]string, error) {
 	for _, file := range filenames {
 		routines, err := r.scan(file)
 		if err != nil {
-			return []string{}, err
+			return []string{}, []string{}, err
 		}
 
 		for key, value := range routines {
 			statements[key] = append(statements[key], value)
+			files[key] = append(files[key], file)
 		}
 	}
 
 	routine, ok := statements[name]
 	if !ok {
-		return []string{}, fmt.Errorf("routine '%s' not found for migration '%v'", name, m)
+		return []string{}, []string{}, fmt.Errorf("routine '%s' not found for migration '%v'", name, m)
 	}
 
 	queries := []string{}
-	splitter := &sqlexec.Splitter{}
+	splitter := &sqlexec.Splitter{Dialect: r.DB.DriverName()}
 
 	for _, body := range routine {
 		stmt := splitter.Split(bytes.NewBufferString(body))
 		queries = append(queries, stmt...)
 	}
 
-	return queries, nil
+	return files[name], queries, nil
+}
+
+func (r *Runner) transactional(statements []string) bool {
+	if r.DB.DriverName() != "mysql" {
+		return true
+	}
+
+	for _, query := range statements {
+		if ddlRgxp.MatchString(query) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (r *Runner) scan(filename string) (map[string]string, error) {
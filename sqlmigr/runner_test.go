@@ -2,6 +2,7 @@ package sqlmigr_test
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -80,6 +81,34 @@ var _ = Describe("Runner", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		Context("when the migration has a trigger with a CASE expression", func() {
+			JustBeforeEach(func() {
+				sqlmigr := &bytes.Buffer{}
+				fmt.Fprintln(sqlmigr, "-- name: up")
+				fmt.Fprintln(sqlmigr, "CREATE TABLE test(id TEXT, role TEXT, admin BOOLEAN);")
+				fmt.Fprintln(sqlmigr, "CREATE TRIGGER test_role AFTER INSERT ON test")
+				fmt.Fprintln(sqlmigr, "BEGIN")
+				fmt.Fprintln(sqlmigr, "  UPDATE test SET id = upper(id) WHERE id = NEW.id;")
+				fmt.Fprintln(sqlmigr, "  UPDATE test SET role = CASE WHEN admin THEN 'admin' ELSE 'user' END;")
+				fmt.Fprintln(sqlmigr, "  UPDATE test SET admin = 0 WHERE role = 'user';")
+				fmt.Fprintln(sqlmigr, "END;")
+				fmt.Fprintln(sqlmigr, "INSERT INTO test (id, admin) VALUES ('jack', 1);")
+				fmt.Fprintln(sqlmigr, "-- name: down")
+				fmt.Fprintln(sqlmigr, "DROP TABLE IF EXISTS test;")
+
+				path := filepath.Join(dir, item.Filenames()[0])
+				Expect(ioutil.WriteFile(path, sqlmigr.Bytes(), 0700)).To(Succeed())
+			})
+
+			It("runs the trigger as a single statement", func() {
+				Expect(runner.Run(item)).To(Succeed())
+
+				role := ""
+				Expect(runner.DB.Get(&role, "SELECT role FROM test WHERE id = 'JACK'")).To(Succeed())
+				Expect(role).To(Equal("admin"))
+			})
+		})
+
 		Context("when the sqlmigr does not exist", func() {
 			JustBeforeEach(func() {
 				for _, filename := range item.Filenames() {
@@ -107,6 +136,30 @@ var _ = Describe("Runner", func() {
 			})
 		})
 
+		Context("when the schema is set", func() {
+			BeforeEach(func() {
+				runner.Schema = "tenant"
+			})
+
+			It("returns an error", func() {
+				Expect(runner.Run(item)).To(MatchError("schema is not supported by 'sqlite3' driver"))
+			})
+
+			Context("when the driver is postgres", func() {
+				var recorder *Recorder
+
+				BeforeEach(func() {
+					recorder = &Recorder{Path: filepath.Join(dir, "prana.db")}
+					runner.DB = sqlx.NewDb(sql.OpenDB(recorder), "postgres")
+				})
+
+				It("keeps the public schema in the search path", func() {
+					Expect(runner.Run(item)).To(Succeed())
+					Expect(recorder.Statements).To(ContainElement("SET LOCAL search_path TO \"tenant\", public"))
+				})
+			})
+		})
+
 		Context("when the sqlmigr step does not exist", func() {
 			JustBeforeEach(func() {
 				sqlmigr := &bytes.Buffer{}
@@ -182,4 +235,66 @@ var _ = Describe("Runner", func() {
 			})
 		})
 	})
+
+	Describe("Plan", func() {
+		It("returns the plan successfully", func() {
+			plan, err := runner.Plan(item)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(plan.Migration).To(Equal(item))
+			Expect(plan.Files).To(ConsistOf("20160102150_schema.sql"))
+			Expect(plan.Statements).To(HaveLen(2))
+			Expect(plan.Statements[0]).To(ContainSubstring("CREATE TABLE IF NOT EXISTS test(id TEXT);"))
+			Expect(plan.Statements[1]).To(ContainSubstring("CREATE TABLE IF NOT EXISTS test2(id TEXT);"))
+			Expect(plan.Transactional).To(BeTrue())
+		})
+
+		It("does not execute the migration", func() {
+			_, err := runner.Plan(item)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = runner.DB.Exec("SELECT id FROM test")
+			Expect(err).To(MatchError("no such table: test"))
+		})
+
+		Context("when the driver commits DDL statements implicitly", func() {
+			It("returns a non transactional plan", func() {
+				runner.DB = sqlx.NewDb(runner.DB.DB, "mysql")
+
+				plan, err := runner.Plan(item)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.Transactional).To(BeFalse())
+			})
+		})
+
+		Context("when the migration has multiple files", func() {
+			BeforeEach(func() {
+				item.Drivers = []string{"sql", "sqlite3"}
+			})
+
+			It("returns all files", func() {
+				plan, err := runner.Plan(item)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan.Files).To(HaveLen(2))
+				Expect(plan.Files).To(ContainElement("20160102150_schema.sql"))
+				Expect(plan.Files).To(ContainElement("20160102150_schema_sqlite3.sql"))
+			})
+		})
+
+		Context("when the sqlmigr step does not exist", func() {
+			JustBeforeEach(func() {
+				sqlmigr := &bytes.Buffer{}
+				fmt.Fprintln(sqlmigr, "-- name: down")
+				fmt.Fprintln(sqlmigr, "DROP TABLE IF EXISTS test")
+
+				path := filepath.Join(dir, item.Filenames()[0])
+				Expect(ioutil.WriteFile(path, sqlmigr.Bytes(), 0700)).To(Succeed())
+			})
+
+			It("return an error", func() {
+				plan, err := runner.Plan(item)
+				Expect(err).To(MatchError("routine 'up' not found for migration '20160102150_schema'"))
+				Expect(plan).To(BeNil())
+			})
+		})
+	})
 })
@@ -0,0 +1,24 @@
+package sqlmigr
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Schemas returns the names of the database schemas that match the given
+// LIKE pattern. It's supported only by PostgreSQL.
+func Schemas(db *sqlx.DB, pattern string) ([]string, error) {
+	if driver := db.DriverName(); driver != "postgres" {
+		return []string{}, fmt.Errorf("schema is not supported by '%s' driver", driver)
+	}
+
+	query := "SELECT schema_name FROM information_schema.schemata WHERE schema_name LIKE $1 ORDER BY schema_name"
+	schemas := []string{}
+
+	if err := db.Select(&schemas, query, pattern); err != nil {
+		return []string{}, err
+	}
+
+	return schemas, nil
+}
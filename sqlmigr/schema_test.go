@@ -0,0 +1,23 @@
+package sqlmigr_test
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlmigr"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schemas", func() {
+	Context("when the driver does not support schemas", func() {
+		It("returns an error", func() {
+			db, err := sqlx.Open("sqlite3", ":memory:")
+			Expect(err).NotTo(HaveOccurred())
+			defer db.Close()
+
+			schemas, err := sqlmigr.Schemas(db, "tenant_%")
+			Expect(err).To(MatchError("schema is not supported by 'sqlite3' driver"))
+			Expect(schemas).To(BeEmpty())
+		})
+	})
+})
@@ -1,10 +1,14 @@
 package sqlmigr_test
 
 import (
+	"context"
+	"database/sql/driver"
 	"log"
+	"strings"
+	"sync"
 	"testing"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,3 +19,45 @@ func TestMigration(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Migration Suite")
 }
+
+// Recorder connects to SQLite database and records the executed statements.
+// The SET statements of PostgreSQL are only recorded.
+type Recorder struct {
+	Path       string
+	Statements []string
+	mu         sync.Mutex
+}
+
+// Connect returns a connection to the database
+func (r *Recorder) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := r.Driver().Open(r.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecorderConn{Conn: conn, recorder: r}, nil
+}
+
+// Driver returns the SQLite driver
+func (r *Recorder) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+// RecorderConn is a connection that records the executed statements
+type RecorderConn struct {
+	driver.Conn
+	recorder *Recorder
+}
+
+// ExecContext records the statement and executes it
+func (c *RecorderConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.recorder.mu.Lock()
+	c.recorder.Statements = append(c.recorder.Statements, query)
+	c.recorder.mu.Unlock()
+
+	if strings.HasPrefix(query, "SET ") {
+		return driver.ResultNoRows, nil
+	}
+
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
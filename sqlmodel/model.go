@@ -233,6 +233,12 @@ type ColumnType struct {
 	Precision int
 	// PrecisionScale for numeric type
 	PrecisionScale int
+	// Default is the SQL expression of the column default value. It's empty
+	// if the column does not have a default value.
+	Default string
+	// IsAutoIncrement returns true if the column value is generated by a
+	// sequence (e.g. serial, identity or AUTO_INCREMENT column)
+	IsAutoIncrement bool
 }
 
 // DBType returns the db type as string
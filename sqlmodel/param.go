@@ -0,0 +1,135 @@
+package sqlmodel
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/phogolabs/prana/sqlexec"
+)
+
+var (
+	tableRgxp      = regexp.MustCompile("(?i)\\b(?:FROM|INTO|UPDATE|JOIN)\\s+([A-Za-z0-9_.\"`]+)")
+	comparisonRgxp = regexp.MustCompile("([A-Za-z0-9_.\"`]+)\\s*(?:=|<>|!=|<=|>=|<|>|(?i:\\bLIKE\\b))\\s*:([A-Za-z_][A-Za-z0-9_]*)")
+)
+
+// ParamTypes infers the types of the named parameters of a query from the
+// columns of the tables that the query targets. A parameter matches a
+// column with the same name or a column that it's compared with. The
+// parameters that do not match any column are omitted.
+func ParamTypes(provider SchemaProvider, schema, query string) (map[string]string, error) {
+	types := map[string]string{}
+
+	names := sqlexec.ParamNames(query)
+	if len(names) == 0 {
+		return types, nil
+	}
+
+	tables := queryTables(query)
+	if len(tables) == 0 {
+		return types, nil
+	}
+
+	definition, err := provider.Schema(schema, tables...)
+	if err != nil {
+		return types, err
+	}
+
+	columns := map[string]*Column{}
+
+	for _, table := range definition.Tables {
+		for index := range table.Columns {
+			column := &table.Columns[index]
+			columns[strings.ToLower(column.Name)] = column
+		}
+	}
+
+	aliases := map[string]string{}
+
+	for _, match := range comparisonRgxp.FindAllStringSubmatch(query, -1) {
+		aliases[match[2]] = unquote(match[1])
+	}
+
+	for _, name := range names {
+		column, ok := columns[strings.ToLower(name)]
+
+		if !ok {
+			column, ok = columns[strings.ToLower(aliases[name])]
+		}
+
+		if ok {
+			types[name] = paramType(column)
+		}
+	}
+
+	return types, nil
+}
+
+func queryTables(query string) []string {
+	tables := []string{}
+	visited := map[string]bool{}
+
+	for _, match := range tableRgxp.FindAllStringSubmatch(query, -1) {
+		name := unquote(match[1])
+
+		if name == "" || visited[name] {
+			continue
+		}
+
+		visited[name] = true
+		tables = append(tables, name)
+	}
+
+	return tables
+}
+
+// unquote returns the name without quotes and schema or table qualifier
+func unquote(name string) string {
+	if index := strings.LastIndex(name, "."); index >= 0 {
+		name = name[index+1:]
+	}
+
+	return strings.Trim(name, "\"`")
+}
+
+// scanKinds are the kinds of the scan types of the columns
+var scanKinds = map[string]reflect.Kind{
+	"bool":    reflect.Bool,
+	"int":     reflect.Int,
+	"int8":    reflect.Int8,
+	"int16":   reflect.Int16,
+	"int32":   reflect.Int32,
+	"int64":   reflect.Int64,
+	"uint":    reflect.Uint,
+	"uint8":   reflect.Uint8,
+	"uint16":  reflect.Uint16,
+	"uint32":  reflect.Uint32,
+	"uint64":  reflect.Uint64,
+	"float32": reflect.Float32,
+	"float64": reflect.Float64,
+}
+
+func paramType(column *Column) string {
+	switch strings.ToLower(column.Type.Name) {
+	case "json", "jsonb":
+		return sqlexec.TypeJSON
+	}
+
+	scan := strings.ToLower(strings.TrimPrefix(column.ScanType, "*"))
+
+	if scan == "time.time" {
+		return sqlexec.TypeTime
+	}
+
+	switch scanKinds[scan] {
+	case reflect.Bool:
+		return sqlexec.TypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return sqlexec.TypeInt
+	case reflect.Float32, reflect.Float64:
+		return sqlexec.TypeFloat
+	default:
+		return sqlexec.TypeString
+	}
+}
@@ -0,0 +1,111 @@
+package sqlmodel_test
+
+import (
+	"fmt"
+
+	"github.com/phogolabs/prana/fake"
+	"github.com/phogolabs/prana/sqlexec"
+	"github.com/phogolabs/prana/sqlmodel"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParamTypes", func() {
+	var provider *fake.SchemaProvider
+
+	BeforeEach(func() {
+		provider = &fake.SchemaProvider{}
+		provider.SchemaReturns(&sqlmodel.Schema{
+			Tables: []sqlmodel.Table{
+				{
+					Name: "users",
+					Columns: []sqlmodel.Column{
+						{Name: "id", ScanType: "int"},
+						{Name: "name", ScanType: "string"},
+						{Name: "active", ScanType: "*bool"},
+						{Name: "deleted_at", ScanType: "*time.Time"},
+						{Name: "data", ScanType: "[]byte", Type: sqlmodel.ColumnType{Name: "jsonb"}},
+					},
+				},
+			},
+		}, nil)
+	})
+
+	It("infers the types from the columns", func() {
+		query := "UPDATE users SET name = :name, active = :active, deleted_at = :deleted_at, data = :data WHERE id = :user_id AND name <> :unknown_column"
+
+		types, err := sqlmodel.ParamTypes(provider, "", query)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(types).To(Equal(map[string]string{
+			"name":           sqlexec.TypeString,
+			"active":         sqlexec.TypeBool,
+			"deleted_at":     sqlexec.TypeTime,
+			"data":           sqlexec.TypeJSON,
+			"user_id":        sqlexec.TypeInt,
+			"unknown_column": sqlexec.TypeString,
+		}))
+
+		Expect(provider.SchemaCallCount()).To(Equal(1))
+		schema, tables := provider.SchemaArgsForCall(0)
+		Expect(schema).To(BeEmpty())
+		Expect(tables).To(Equal([]string{"users"}))
+	})
+
+	Context("when the columns have unsigned and interface scan types", func() {
+		BeforeEach(func() {
+			provider.SchemaReturns(&sqlmodel.Schema{
+				Tables: []sqlmodel.Table{
+					{
+						Name: "counters",
+						Columns: []sqlmodel.Column{
+							{Name: "value", ScanType: "*uint64"},
+							{Name: "size", ScanType: "Uint"},
+							{Name: "meta", ScanType: "interface{}"},
+							{Name: "point", ScanType: "schema.Point"},
+						},
+					},
+				},
+			}, nil)
+		})
+
+		It("infers the types by the kinds of the scan types", func() {
+			query := "UPDATE counters SET value = :value, size = :size, meta = :meta, point = :point"
+
+			types, err := sqlmodel.ParamTypes(provider, "", query)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(types).To(Equal(map[string]string{
+				"value": sqlexec.TypeInt,
+				"size":  sqlexec.TypeInt,
+				"meta":  sqlexec.TypeString,
+				"point": sqlexec.TypeString,
+			}))
+		})
+	})
+
+	Context("when the query does not have named parameters", func() {
+		It("does not query the schema", func() {
+			types, err := sqlmodel.ParamTypes(provider, "", "SELECT * FROM users WHERE id = ?")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(types).To(BeEmpty())
+			Expect(provider.SchemaCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the parameter does not match a column", func() {
+		It("omits the parameter", func() {
+			types, err := sqlmodel.ParamTypes(provider, "", `SELECT * FROM "public"."users" WHERE id + 1 > :limit`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(types).To(BeEmpty())
+		})
+	})
+
+	Context("when the provider fails", func() {
+		It("returns the error", func() {
+			provider.SchemaReturns(nil, fmt.Errorf("oh no!"))
+
+			_, err := sqlmodel.ParamTypes(provider, "", "SELECT * FROM users WHERE id = :id")
+			Expect(err).To(MatchError("oh no!"))
+		})
+	})
+})
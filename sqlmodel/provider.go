@@ -2,6 +2,7 @@ package sqlmodel
 
 import (
 	"bytes"
+	"database/sql"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -291,7 +292,9 @@ func (m *PostgreSQLProvider) Schema(schema string, names ...string) (*Schema, er
 	query.WriteString("SELECT column_name, data_type, udt_name, is_nullable = 'YES' AS is_nullable, ")
 	query.WriteString("CASE WHEN numeric_precision IS NULL THEN 0 ELSE numeric_precision END, ")
 	query.WriteString("CASE WHEN numeric_scale IS NULL THEN 0 ELSE numeric_scale END, ")
-	query.WriteString("CASE WHEN character_maximum_length IS NULL THEN 0 ELSE character_maximum_length END ")
+	query.WriteString("CASE WHEN character_maximum_length IS NULL THEN 0 ELSE character_maximum_length END, ")
+	query.WriteString("COALESCE(column_default, ''), ")
+	query.WriteString("is_identity = 'YES' OR COALESCE(column_default, '') LIKE 'nextval(%' AS is_auto_increment ")
 	query.WriteString("FROM information_schema.columns ")
 	query.WriteString("WHERE table_schema = $1 AND table_name = $2 ")
 	query.WriteString("ORDER BY table_schema, table_name, ordinal_position")
@@ -324,10 +327,17 @@ func (m *PostgreSQLProvider) Schema(schema string, names ...string) (*Schema, er
 				&column.Type.Precision,
 				&column.Type.PrecisionScale,
 				&column.Type.CharMaxLength,
+				&column.Type.Default,
+				&column.Type.IsAutoIncrement,
 			}
 
 			_ = rows.Scan(fields...)
 
+			// the sequence belongs to the database
+			if column.Type.IsAutoIncrement {
+				column.Type.Default = ""
+			}
+
 			column.Type.IsPrimaryKey = contains(primaryKey, column.Name)
 			column.ScanType = m.translate(&column.Type)
 			table.Columns = append(table.Columns, column)
@@ -479,6 +489,8 @@ func (m *SQLiteProvider) Schema(schema string, names ...string) (*Schema, error)
 			table.Columns = append(table.Columns, column)
 		}
 
+		m.autoIncrement(&table)
+
 		tables = append(tables, table)
 	}
 
@@ -520,19 +532,43 @@ func (m *SQLiteProvider) create(info *sqliteInf) ColumnType {
 		}
 	}
 
+	if value, ok := info.DefaultValue.([]byte); ok {
+		info.DefaultValue = string(value)
+	}
+
 	columnType := ColumnType{
 		Name:           info.Type,
 		Underlying:     info.Type,
-		IsPrimaryKey:   info.PK == 1,
+		IsPrimaryKey:   info.PK > 0,
 		IsNullable:     info.NotNullable == 0,
 		CharMaxLength:  max,
 		Precision:      precision,
 		PrecisionScale: precisionScale,
 	}
 
+	if value, ok := info.DefaultValue.(string); ok && !strings.EqualFold(value, "NULL") {
+		columnType.Default = value
+	}
+
 	return columnType
 }
 
+// autoIncrement marks the single INTEGER PRIMARY KEY column, which is an
+// alias of the row id that is generated by SQLite
+func (m *SQLiteProvider) autoIncrement(table *Table) {
+	var keys []*Column
+
+	for index := range table.Columns {
+		if column := &table.Columns[index]; column.Type.IsPrimaryKey {
+			keys = append(keys, column)
+		}
+	}
+
+	if len(keys) == 1 && keys[0].Type.Name == "integer" {
+		keys[0].Type.IsAutoIncrement = true
+	}
+}
+
 // MySQLProvider represents a metadata provider for MySQL
 type MySQLProvider struct {
 	// DB is a connection to MySQL database
@@ -602,7 +638,8 @@ func (m *MySQLProvider) Schema(schema string, names ...string) (*Schema, error)
 	query.WriteString("INSTR(column_type, 'unsigned') > 0 AS is_unsigned, ")
 	query.WriteString("CASE WHEN numeric_precision IS NULL THEN 0 ELSE numeric_precision END, ")
 	query.WriteString("CASE WHEN numeric_scale IS NULL THEN 0 ELSE numeric_scale END, ")
-	query.WriteString("CASE WHEN character_maximum_length IS NULL THEN 0 ELSE character_maximum_length END ")
+	query.WriteString("CASE WHEN character_maximum_length IS NULL THEN 0 ELSE character_maximum_length END, ")
+	query.WriteString("column_default, extra ")
 	query.WriteString("FROM information_schema.columns ")
 	query.WriteString("WHERE table_schema = ? AND table_name = ? ")
 	query.WriteString("ORDER BY table_schema, table_name, ordinal_position")
@@ -625,7 +662,11 @@ func (m *MySQLProvider) Schema(schema string, names ...string) (*Schema, error)
 		}
 
 		for rows.Next() {
-			column := Column{}
+			var (
+				column = Column{}
+				value  sql.NullString
+				extra  string
+			)
 
 			fields := []interface{}{
 				&column.Name,
@@ -636,10 +677,17 @@ func (m *MySQLProvider) Schema(schema string, names ...string) (*Schema, error)
 				&column.Type.Precision,
 				&column.Type.PrecisionScale,
 				&column.Type.CharMaxLength,
+				&value,
+				&extra,
 			}
 
 			_ = rows.Scan(fields...)
 
+			extra = strings.ToLower(extra)
+
+			column.Type.IsAutoIncrement = strings.Contains(extra, "auto_increment")
+			column.Type.Default = m.defaultValue(&column.Type, value, extra)
+
 			column.Type.IsPrimaryKey = contains(primaryKey, column.Name)
 			column.ScanType = translate(&column.Type)
 			table.Columns = append(table.Columns, column)
@@ -662,6 +710,30 @@ func (m *MySQLProvider) Schema(schema string, names ...string) (*Schema, error)
 	return schemaDef, nil
 }
 
+// defaultValue returns the default value of the column as SQL expression. The
+// string defaults are not quoted by MySQL, unlike the expressions that are
+// marked as generated.
+func (m *MySQLProvider) defaultValue(columnType *ColumnType, value sql.NullString, extra string) string {
+	switch {
+	case !value.Valid || strings.EqualFold(value.String, "NULL"):
+		return ""
+	case strings.Contains(extra, "default_generated"):
+		return value.String
+	case strings.HasPrefix(strings.ToUpper(value.String), "CURRENT_TIMESTAMP"):
+		return value.String
+	}
+
+	if _, err := strconv.ParseFloat(value.String, 64); err == nil {
+		switch strings.ToLower(columnType.Name) {
+		case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set":
+		default:
+			return value.String
+		}
+	}
+
+	return "'" + strings.Replace(value.String, "'", "''", -1) + "'"
+}
+
 func (m *MySQLProvider) database() (string, error) {
 	schema := ""
 	row := m.DB.QueryRow("SELECT database()")
@@ -776,6 +776,31 @@ var _ = Describe("SQLiteProvider", func() {
 			})
 		})
 
+		Context("when the columns have default values", func() {
+			BeforeEach(func() {
+				_, err := db.Exec("CREATE TABLE my_table(id integer primary key, name text default 'none', score integer)")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				_, err := db.Exec("DROP TABLE IF EXISTS my_table")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns the default values and the auto increment column", func() {
+				schema, err := provider.Schema("", "my_table")
+				Expect(err).NotTo(HaveOccurred())
+
+				columns := schema.Tables[0].Columns
+				Expect(columns).To(HaveLen(3))
+				Expect(columns[0].Type.IsAutoIncrement).To(BeTrue())
+				Expect(columns[0].Type.Default).To(BeEmpty())
+				Expect(columns[1].Type.IsAutoIncrement).To(BeFalse())
+				Expect(columns[1].Type.Default).To(Equal("'none'"))
+				Expect(columns[2].Type.Default).To(BeEmpty())
+			})
+		})
+
 		Context("when the table names are not provided", func() {
 			It("return an error", func() {
 				schema, err := provider.Schema("public")
@@ -0,0 +1,557 @@
+package sqlmodel
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"go/token"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aymerick/raymond"
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/sqlexec"
+)
+
+var initialisms = map[string]bool{
+	"api":  true,
+	"html": true,
+	"http": true,
+	"id":   true,
+	"ip":   true,
+	"json": true,
+	"pk":   true,
+	"sql":  true,
+	"uri":  true,
+	"url":  true,
+	"uuid": true,
+	"xml":  true,
+}
+
+var (
+	// errSideEffects is returned for the routines that return rows and
+	// modify the data
+	errSideEffects = fmt.Errorf("the result columns of a statement with side effects cannot be inferred")
+	// errTemplate is returned for the templated routines
+	errTemplate = fmt.Errorf("the templated routines do not have a static query")
+	// errNoColumns is returned for the queries that cannot be used as a
+	// subquery (e.g. SHOW or PRAGMA statements)
+	errNoColumns = fmt.Errorf("the result columns of the statement cannot be inferred")
+)
+
+// selectRgxp matches the queries whose result columns can be inferred
+var selectRgxp = regexp.MustCompile(`(?is)^(\s|--[^\n]*(\n|$)|/\*.*?\*/)*(SELECT|WITH|VALUES|TABLE)\b`)
+
+// RoutineCodegen generates a package of typed Golang functions for the named
+// SQL routines. The result columns are inferred from an empty result of each
+// read-only query, which is limited to zero rows and executed in a
+// transaction that is rolled back. The routines that return rows and modify
+// the data (e.g. INSERT with RETURNING clause) and the templated routines are
+// skipped. The parameter types are taken from the routine annotations or
+// inferred from the database schema.
+type RoutineCodegen struct {
+	// DB is a connection to the database
+	DB *sqlx.DB
+	// Provider provides the schema used to infer the parameter types
+	Provider SchemaProvider
+	// Schema is the name of the database schema
+	Schema string
+	// Package name of the generated code
+	Package string
+	// Skip is called for every routine that is skipped
+	Skip func(routine *sqlexec.Routine, reason error)
+}
+
+// Generate generates the typed functions for given routines
+func (g *RoutineCodegen) Generate(w io.Writer, routines []*sqlexec.Routine) error {
+	var (
+		specs = []*routineSpec{}
+		names = map[string]string{}
+	)
+
+	for _, routine := range routines {
+		spec, err := g.spec(routine)
+
+		switch err {
+		case nil:
+		case errSideEffects, errTemplate, errNoColumns:
+			if g.Skip != nil {
+				g.Skip(routine, err)
+			}
+
+			continue
+		default:
+			return fmt.Errorf("routine '%s': %v", routine.Name, err)
+		}
+
+		// the row type is declared next to the function
+		for _, ident := range []string{spec.Func, spec.Row} {
+			if ident == "" {
+				continue
+			}
+
+			if name, ok := names[ident]; ok {
+				return fmt.Errorf("routines '%s' and '%s' have the same Golang identifier '%s'", name, routine.Name, ident)
+			}
+
+			names[ident] = routine.Name
+		}
+
+		specs = append(specs, spec)
+	}
+
+	generator := &Codegen{}
+
+	template, err := generator.template("query")
+	if err != nil {
+		return err
+	}
+
+	param := map[string]interface{}{
+		"Package":  g.Package,
+		"Routines": specs,
+	}
+
+	result, err := raymond.Render(template, param)
+	if err != nil {
+		return err
+	}
+
+	buffer := bytes.NewBufferString(result)
+
+	// the imports are always processed as the generated code depends on the
+	// result and parameter types
+	if err := generator.format("query", buffer); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, buffer)
+	return err
+}
+
+type routineSpec struct {
+	Name    string
+	Func    string
+	Doc     []string
+	Literal string
+	Params  []*routineParam
+	Args    string
+	Returns string
+	Result  string
+	Row     string
+	Columns []*routineColumn
+	Timeout int64
+}
+
+type routineParam struct {
+	Name  string
+	Ident string
+	Type  string
+}
+
+type routineColumn struct {
+	Name  string
+	Field string
+	Type  string
+	Kind  *sql.ColumnType
+}
+
+func (g *RoutineCodegen) spec(routine *sqlexec.Routine) (*routineSpec, error) {
+	if sqlexec.IsTemplate(routine.Query) {
+		return nil, errTemplate
+	}
+
+	spec := &routineSpec{
+		Name:    routine.Name,
+		Func:    identifier(routine.Name, true),
+		Returns: routine.Returns,
+		Timeout: int64(routine.Timeout),
+	}
+
+	if routine.Doc != "" {
+		spec.Doc = strings.Split(routine.Doc, "\n")
+		spec.Doc[0] = fmt.Sprintf("%s %s", spec.Func, spec.Doc[0])
+	}
+
+	if spec.Returns == "" {
+		if sqlexec.IsQuery(routine.Query) {
+			spec.Returns = sqlexec.ReturnsMany
+		} else {
+			spec.Returns = sqlexec.ReturnsExec
+		}
+	}
+
+	query, args, err := g.params(spec, routine)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.Literal = literal(query)
+
+	if len(args) > 0 {
+		spec.Args = ", " + strings.Join(args, ", ")
+	}
+
+	switch spec.Returns {
+	case sqlexec.ReturnsExec:
+		spec.Result = "sql.Result"
+		return spec, nil
+	case sqlexec.ReturnsOne:
+		spec.Row = spec.Func + "Row"
+		spec.Result = "*" + spec.Row
+	default:
+		spec.Row = spec.Func + "Row"
+		spec.Result = "[]*" + spec.Row
+	}
+
+	// the statements are executed in order to get their result columns
+	if !sqlexec.IsReadOnly(routine.Query) {
+		return nil, errSideEffects
+	}
+
+	if !selectRgxp.MatchString(routine.Query) {
+		return nil, errNoColumns
+	}
+
+	columns, err := g.columns(query, len(args))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.nullable(routine.Query, columns); err != nil {
+		return nil, err
+	}
+
+	spec.Columns = columns
+	return spec, nil
+}
+
+// params returns the query with positional placeholders and the identifiers
+// of the function parameters in the order of the placeholders
+func (g *RoutineCodegen) params(spec *routineSpec, routine *sqlexec.Routine) (string, []string, error) {
+	var (
+		query    = routine.Query
+		args     = []string{}
+		names    = sqlexec.ParamNames(query)
+		declared = map[string]string{}
+	)
+
+	for _, param := range routine.Params {
+		declared[param.Name] = param.Type
+	}
+
+	if len(names) == 0 {
+		for index := 0; index < sqlexec.Placeholders(query); index++ {
+			param := &routineParam{
+				Name: fmt.Sprintf("arg%d", index+1),
+				Type: "interface{}",
+			}
+
+			if index < len(routine.Params) {
+				kind, err := goType(routine.Params[index].Type)
+				if err != nil {
+					return "", nil, err
+				}
+
+				param.Name = routine.Params[index].Name
+				param.Type = kind
+			}
+
+			param.Ident = identifier(param.Name, false)
+			spec.Params = append(spec.Params, param)
+			args = append(args, param.Ident)
+		}
+
+		return query, args, nil
+	}
+
+	types := map[string]string{}
+
+	if g.Provider != nil {
+		inferred, err := ParamTypes(g.Provider, g.Schema, query)
+		if err != nil {
+			return "", nil, err
+		}
+
+		types = inferred
+	}
+
+	values := map[string]interface{}{}
+
+	for _, name := range names {
+		kind, ok := declared[name]
+		if !ok || kind == "" {
+			kind = types[name]
+		}
+
+		kind, err := goType(kind)
+		if err != nil {
+			return "", nil, err
+		}
+
+		param := &routineParam{
+			Name:  name,
+			Ident: identifier(name, false),
+			Type:  kind,
+		}
+
+		spec.Params = append(spec.Params, param)
+		values[name] = param.Ident
+	}
+
+	// the identifiers are bound as values in order to get them in the order
+	// of the placeholders
+	query, params, err := sqlexec.BindNamed(query, values)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, param := range params {
+		args = append(args, fmt.Sprintf("%v", param))
+	}
+
+	return query, args, nil
+}
+
+// columns returns the result columns of the read-only query. The query is
+// wrapped as a subquery limited to zero rows, so that no rows are read, and
+// executed with NULL parameters in a transaction that is rolled back.
+func (g *RoutineCodegen) columns(query string, count int) ([]*routineColumn, error) {
+	tx, err := g.DB.BeginTxx(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var (
+		args  = make([]interface{}, count)
+		inner = strings.TrimRight(strings.TrimSpace(query), ";")
+	)
+
+	query = fmt.Sprintf("SELECT * FROM (\n%s\n) AS prana_columns LIMIT 0", inner)
+
+	rows, err := tx.Queryx(g.DB.Rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	kinds, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := []*routineColumn{}
+
+	for _, kind := range kinds {
+		column := &routineColumn{
+			Name:  kind.Name(),
+			Field: identifier(kind.Name(), true),
+		}
+
+		nullable, ok := kind.Nullable()
+
+		column.Kind = kind
+		column.Type = columnType(kind, nullable || !ok)
+
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// nullable resolves the nullability of the columns from the columns of the
+// tables that the query targets, because some database drivers (e.g. SQLite)
+// report every result column as nullable
+func (g *RoutineCodegen) nullable(query string, columns []*routineColumn) error {
+	if g.Provider == nil {
+		return nil
+	}
+
+	tables := queryTables(query)
+	if len(tables) == 0 {
+		return nil
+	}
+
+	definition, err := g.Provider.Schema(g.Schema, tables...)
+	if err != nil {
+		return err
+	}
+
+	nullable := map[string]bool{}
+
+	for _, table := range definition.Tables {
+		for _, column := range table.Columns {
+			nullable[strings.ToLower(column.Name)] = column.Type.IsNullable
+		}
+	}
+
+	for _, column := range columns {
+		if value, ok := nullable[strings.ToLower(column.Name)]; ok {
+			column.Type = columnType(column.Kind, value)
+		}
+	}
+
+	return nil
+}
+
+func columnType(kind *sql.ColumnType, nullable bool) string {
+	name := kind.DatabaseTypeName()
+
+	if name == "" {
+		scan := kind.ScanType()
+
+		if scan != nil && scan.Kind() == reflect.Ptr {
+			scan = scan.Elem()
+		}
+
+		if scan == nil || scan.Kind() == reflect.Interface {
+			return "interface{}"
+		}
+
+		return scan.String()
+	}
+
+	if index := strings.Index(name, "("); index > 0 {
+		name = name[:index]
+	}
+
+	return translate(&ColumnType{
+		Name:       strings.TrimSpace(name),
+		Underlying: strings.ToLower(kind.DatabaseTypeName()),
+		IsNullable: nullable,
+	})
+}
+
+// goTypes are the Golang types of the SQL types of the annotated parameters
+var goTypes = map[string]string{
+	"text":                        "string",
+	"varchar":                     "string",
+	"char":                        "string",
+	"character":                   "string",
+	"character varying":           "string",
+	"uuid":                        "string",
+	"numeric":                     "string",
+	"decimal":                     "string",
+	"integer":                     "int64",
+	"smallint":                    "int64",
+	"bigint":                      "int64",
+	"tinyint":                     "int64",
+	"mediumint":                   "int64",
+	"int2":                        "int64",
+	"int4":                        "int64",
+	"int8":                        "int64",
+	"serial":                      "int64",
+	"bigserial":                   "int64",
+	"real":                        "float64",
+	"double":                      "float64",
+	"double precision":            "float64",
+	"float4":                      "float64",
+	"float8":                      "float64",
+	"boolean":                     "bool",
+	"date":                        "time.Time",
+	"time":                        "time.Time",
+	"datetime":                    "time.Time",
+	"timestamp":                   "time.Time",
+	"timestamptz":                 "time.Time",
+	"timestamp with time zone":    "time.Time",
+	"timestamp without time zone": "time.Time",
+	"blob":                        "[]byte",
+	"bytea":                       "[]byte",
+	"binary":                      "[]byte",
+	"varbinary":                   "[]byte",
+	"jsonb":                       "[]byte",
+}
+
+// goType returns the Golang type of the parameter type, which is either a
+// parameter type of sqlexec package or a SQL type
+func goType(kind string) (string, error) {
+	switch kind {
+	case "", sqlexec.TypeNull:
+		return "interface{}", nil
+	case sqlexec.TypeInt:
+		return "int64", nil
+	case sqlexec.TypeFloat:
+		return "float64", nil
+	case sqlexec.TypeBool:
+		return "bool", nil
+	case sqlexec.TypeTime:
+		return "time.Time", nil
+	case sqlexec.TypeJSON:
+		return "[]byte", nil
+	case sqlexec.TypeString:
+		return "string", nil
+	}
+
+	name := strings.ToLower(strings.TrimSpace(kind))
+
+	// the size and the precision do not change the type
+	if index := strings.Index(name, "("); index > 0 {
+		name = strings.TrimSpace(name[:index])
+	}
+
+	if value, ok := goTypes[name]; ok {
+		return value, nil
+	}
+
+	return "", fmt.Errorf("unsupported parameter type '%s'", kind)
+}
+
+// identifier returns a Golang identifier for given name
+func identifier(name string, exported bool) string {
+	words := strings.FieldsFunc(name, func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	})
+
+	buffer := &strings.Builder{}
+
+	for index, word := range words {
+		word = strings.ToLower(word)
+
+		switch {
+		case index == 0 && !exported:
+			buffer.WriteString(word)
+		case initialisms[word]:
+			buffer.WriteString(strings.ToUpper(word))
+		default:
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			buffer.WriteString(string(runes))
+		}
+	}
+
+	ident := buffer.String()
+
+	switch {
+	case ident == "":
+		ident = "value"
+	case unicode.IsDigit([]rune(ident)[0]):
+		ident = "_" + ident
+	}
+
+	if !exported && (token.Lookup(ident).IsKeyword() || ident == "ctx" || ident == "db") {
+		ident = ident + "Param"
+	}
+
+	return ident
+}
+
+// literal returns the query as Golang string literal
+func literal(query string) string {
+	if strings.Contains(query, "`") {
+		return strconv.Quote(query)
+	}
+
+	return "`" + query + "`"
+}
@@ -0,0 +1,303 @@
+package sqlmodel_test
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/phogolabs/prana/fake"
+	"github.com/phogolabs/prana/sqlexec"
+	"github.com/phogolabs/prana/sqlmodel"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RoutineCodegen", func() {
+	var (
+		db        *sqlx.DB
+		provider  *fake.SchemaProvider
+		generator *sqlmodel.RoutineCodegen
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		db, err = sqlx.Open("sqlite3", ":memory:")
+		Expect(err).NotTo(HaveOccurred())
+		db.SetMaxOpenConns(1)
+
+		_, err = db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY NOT NULL, name TEXT NOT NULL, email TEXT)")
+		Expect(err).NotTo(HaveOccurred())
+
+		provider = &fake.SchemaProvider{}
+		provider.SchemaReturns(&sqlmodel.Schema{
+			Tables: []sqlmodel.Table{
+				{
+					Name: "users",
+					Columns: []sqlmodel.Column{
+						{Name: "id", ScanType: "int"},
+						{Name: "name", ScanType: "string"},
+						{Name: "email", ScanType: "*string", Type: sqlmodel.ColumnType{IsNullable: true}},
+					},
+				},
+			},
+		}, nil)
+
+		generator = &sqlmodel.RoutineCodegen{
+			DB:       db,
+			Provider: provider,
+			Package:  "routine",
+		}
+	})
+
+	AfterEach(func() {
+		Expect(db.Close()).To(Succeed())
+	})
+
+	It("generates a function that returns a single row", func() {
+		routines := []*sqlexec.Routine{
+			{
+				Name:    "select-user-by-pk",
+				Doc:     "returns a user for given id",
+				Returns: sqlexec.ReturnsOne,
+				Query:   "SELECT id, name, email FROM users WHERE id = :id",
+			},
+		}
+
+		buffer := &bytes.Buffer{}
+		Expect(generator.Generate(buffer, routines)).To(Succeed())
+
+		source := buffer.String()
+		Expect(source).To(ContainSubstring("package routine"))
+		Expect(source).To(ContainSubstring(`SelectUserByPKRoutine = "select-user-by-pk"`))
+		Expect(source).To(ContainSubstring("const SelectUserByPKQuery = `SELECT id, name, email FROM users WHERE id = ?`"))
+		Expect(source).To(ContainSubstring("type SelectUserByPKRow struct"))
+		Expect(source).To(MatchRegexp("ID\\s+int\\s+`db:\"id\"`"))
+		Expect(source).To(MatchRegexp("Name\\s+string\\s+`db:\"name\"`"))
+		Expect(source).To(MatchRegexp("Email\\s+\\*string\\s+`db:\"email\"`"))
+		Expect(source).To(ContainSubstring("// SelectUserByPK returns a user for given id"))
+		Expect(source).To(ContainSubstring("func SelectUserByPK(ctx context.Context, db sqlx.ExtContext, id int64) (*SelectUserByPKRow, error)"))
+		Expect(source).To(ContainSubstring("sqlx.GetContext(ctx, db, row, db.Rebind(SelectUserByPKQuery), id)"))
+	})
+
+	It("generates a function that returns many rows", func() {
+		routines := []*sqlexec.Routine{
+			{
+				Name:  "select-users-by-name",
+				Query: "SELECT id FROM users WHERE name = :user_name OR email = :user_name",
+			},
+		}
+
+		buffer := &bytes.Buffer{}
+		Expect(generator.Generate(buffer, routines)).To(Succeed())
+
+		source := buffer.String()
+		Expect(source).To(ContainSubstring("func SelectUsersByName(ctx context.Context, db sqlx.ExtContext, userName string) ([]*SelectUsersByNameRow, error)"))
+		Expect(source).To(ContainSubstring("sqlx.SelectContext(ctx, db, &rows, db.Rebind(SelectUsersByNameQuery), userName, userName)"))
+	})
+
+	It("generates a function that executes a statement", func() {
+		routines := []*sqlexec.Routine{
+			{
+				Name:    "delete-user",
+				Params:  []*sqlexec.RoutineParam{{Name: "id", Type: "int"}},
+				Timeout: time.Second,
+				Query:   "DELETE FROM users WHERE id = ?",
+			},
+		}
+
+		buffer := &bytes.Buffer{}
+		Expect(generator.Generate(buffer, routines)).To(Succeed())
+
+		source := buffer.String()
+		Expect(source).To(ContainSubstring("func DeleteUser(ctx context.Context, db sqlx.ExtContext, id int64) (sql.Result, error)"))
+		Expect(source).To(ContainSubstring("context.WithTimeout(ctx, time.Duration(1000000000))"))
+		Expect(source).To(ContainSubstring("return db.ExecContext(ctx, db.Rebind(DeleteUserQuery), id)"))
+		Expect(source).To(ContainSubstring(`"database/sql"`))
+		Expect(provider.SchemaCallCount()).To(BeZero())
+	})
+
+	Context("when the positional parameters are not declared", func() {
+		It("generates untyped parameters", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:  "update-user",
+					Query: "UPDATE users SET name = ? WHERE id = ?",
+				},
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(Succeed())
+			Expect(buffer.String()).To(ContainSubstring("func UpdateUser(ctx context.Context, db sqlx.ExtContext, arg1 interface{}, arg2 interface{}) (sql.Result, error)"))
+		})
+	})
+
+	Context("when the parameters are annotated with SQL types", func() {
+		It("generates the parameters with Golang types", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:   "update-user",
+					Params: []*sqlexec.RoutineParam{{Name: "name", Type: "VARCHAR(64)"}, {Name: "id", Type: "integer"}},
+					Query:  "UPDATE users SET name = ? WHERE id = ?",
+				},
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(Succeed())
+			Expect(buffer.String()).To(ContainSubstring("func UpdateUser(ctx context.Context, db sqlx.ExtContext, name string, id int64) (sql.Result, error)"))
+		})
+
+		Context("when the type is unknown", func() {
+			It("returns an error", func() {
+				routines := []*sqlexec.Routine{
+					{
+						Name:   "update-user",
+						Params: []*sqlexec.RoutineParam{{Name: "name", Type: "geometry"}},
+						Query:  "UPDATE users SET name = ?",
+					},
+				}
+
+				buffer := &bytes.Buffer{}
+				Expect(generator.Generate(buffer, routines)).To(MatchError("routine 'update-user': unsupported parameter type 'geometry'"))
+			})
+		})
+	})
+
+	Context("when the routine returns rows and modifies the data", func() {
+		It("skips the routine", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:  "insert-user",
+					Query: "INSERT INTO users (id, name) VALUES (:id, :name) RETURNING id",
+				},
+				{
+					Name:  "select-users",
+					Query: "SELECT id FROM users",
+				},
+			}
+
+			skipped := []string{}
+
+			generator.Skip = func(routine *sqlexec.Routine, reason error) {
+				skipped = append(skipped, routine.Name)
+				Expect(reason).To(MatchError("the result columns of a statement with side effects cannot be inferred"))
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(Succeed())
+			Expect(buffer.String()).NotTo(ContainSubstring("InsertUser"))
+			Expect(buffer.String()).To(ContainSubstring("func SelectUsers("))
+			Expect(skipped).To(Equal([]string{"insert-user"}))
+		})
+	})
+
+	Context("when the query contains a backtick", func() {
+		It("quotes the query", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:  "select-all-users",
+					Query: "SELECT `name` FROM users",
+				},
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(Succeed())
+			Expect(buffer.String()).To(ContainSubstring("const SelectAllUsersQuery = \"SELECT `name` FROM users\""))
+		})
+	})
+
+	Context("when the routine is a template", func() {
+		It("skips the routine", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:  "search-users",
+					Query: "SELECT * FROM users{{if .name}} WHERE name = {{bind .name}}{{end}}",
+				},
+			}
+
+			skipped := []string{}
+
+			generator.Skip = func(routine *sqlexec.Routine, reason error) {
+				skipped = append(skipped, routine.Name)
+				Expect(reason).To(MatchError("the templated routines do not have a static query"))
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(Succeed())
+			Expect(buffer.String()).NotTo(ContainSubstring("SearchUsers"))
+			Expect(skipped).To(Equal([]string{"search-users"}))
+		})
+	})
+
+	Context("when the query has a common table expression", func() {
+		It("infers the result columns", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:  "select-named-users",
+					Query: "-- named users\nWITH named AS (SELECT id, name FROM users WHERE name <> '') SELECT id, name FROM named;",
+				},
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(Succeed())
+			Expect(buffer.String()).To(MatchRegexp("Name\\s+string\\s+`db:\"name\"`"))
+		})
+	})
+
+	Context("when the query cannot be used as a subquery", func() {
+		It("skips the routine", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:  "show-tables",
+					Query: "PRAGMA table_info(users)",
+				},
+			}
+
+			skipped := []string{}
+
+			generator.Skip = func(routine *sqlexec.Routine, reason error) {
+				skipped = append(skipped, routine.Name)
+				Expect(reason).To(MatchError("the result columns of the statement cannot be inferred"))
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(Succeed())
+			Expect(buffer.String()).NotTo(ContainSubstring("ShowTables"))
+			Expect(skipped).To(Equal([]string{"show-tables"}))
+		})
+	})
+
+	Context("when the routines have the same function name", func() {
+		It("returns an error", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:  "delete-user",
+					Query: "DELETE FROM users WHERE id = ?",
+				},
+				{
+					Name:  "delete_user",
+					Query: "DELETE FROM users WHERE name = ?",
+				},
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(MatchError("routines 'delete-user' and 'delete_user' have the same Golang identifier 'DeleteUser'"))
+		})
+	})
+
+	Context("when the query is invalid", func() {
+		It("returns an error", func() {
+			routines := []*sqlexec.Routine{
+				{
+					Name:    "select-unknown",
+					Returns: sqlexec.ReturnsMany,
+					Query:   "SELECT * FROM unknown",
+				},
+			}
+
+			buffer := &bytes.Buffer{}
+			Expect(generator.Generate(buffer, routines)).To(MatchError(ContainSubstring("routine 'select-unknown': no such table: unknown")))
+		})
+	})
+})